@@ -1,40 +1,352 @@
 package main
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
 	"github.com/gin-gonic/gin"
-	"github.com/glebarez/sqlite"
 	"gorm.io/gorm"
 	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+	"validator-service/internal/config"
+	"validator-service/internal/events"
 	"validator-service/internal/handlers"
 	"validator-service/internal/middlewares"
+	"validator-service/internal/migrations"
 	"validator-service/internal/models"
 	"validator-service/internal/monitoring"
+	"validator-service/internal/queue"
+	"validator-service/internal/repository"
 	"validator-service/internal/routers"
+	"validator-service/internal/services"
+)
+
+const (
+	ShutdownTimeout             = 30 * time.Second
+	IdempotencyKeyPruneInterval = time.Hour
+)
+
+const (
+	GenerateMasterKeyCommand = "generate-master-key"
+	RotateMasterKeyCommand   = "rotate-master-key"
+	MigrateCommand           = "migrate"
+	TenantCommand            = "tenant"
+
+	MigrateUp     = "up"
+	MigrateDown   = "down"
+	MigrateStatus = "status"
+
+	TenantCreate    = "create"
+	TenantAddKey    = "add-key"
+	TenantRevokeKey = "revoke-key"
+	TenantAdopt     = "adopt"
 )
 
 func main() {
+	command := ""
+	if len(os.Args) > 1 {
+		command = os.Args[1]
+	}
+
+	if command == GenerateMasterKeyCommand {
+		generateMasterKey()
+		return
+	}
+
 	monitoring.InitPrometheus()
 
-	var err error
-	db, err := gorm.Open(sqlite.Open("validators.db"), &gorm.Config{})
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	db, err := repository.OpenDatabase(cfg.DBDriver, cfg.DBDSN)
 	if err != nil {
 		log.Fatal(err)
 	}
-	db.AutoMigrate(
-		&models.ValidatorRequest{},
-		&models.ValidatorKey{},
-	)
-	handler := handlers.CreateNewHandler(db)
+
+	if command == MigrateCommand {
+		migrate(db, os.Args[2:])
+		return
+	}
+
+	if command == TenantCommand {
+		manageTenants(db, os.Args[2:])
+		return
+	}
+
+	keyring := setupKeyring(cfg)
+
+	switch command {
+	case "":
+		migrateUp(db)
+	case RotateMasterKeyCommand:
+		rotateMasterKey(db, keyring)
+		return
+	default:
+		log.Fatalf("Unknown command: %s", command)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	bus := events.NewBus()
+	services.SetEventBus(bus)
+
+	pool := startQueue(ctx, db, cfg)
+	webhookPool := startWebhookQueue(ctx, db, cfg)
+	go pruneIdempotencyKeys(ctx, db)
+
+	handler := handlers.CreateNewHandler(db, cfg, pool, bus)
 
 	ginEngine := gin.Default()
 	ginEngine.Use(gin.Logger())
 	ginEngine.Use(gin.Recovery())
 	ginEngine.Use(middlewares.PrometheusMiddleware())
 
-	routers.SetupRoutes(ginEngine, handler)
+	routers.SetupRoutes(ginEngine, handler, middlewares.APIKeyAuth(db))
+
+	server := &http.Server{
+		Addr:    ":8080",
+		Handler: ginEngine,
+	}
+	server.RegisterOnShutdown(handler.CloseStreams)
+
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal(err)
+		}
+	}()
+
+	<-ctx.Done()
+	log.Println("Shutting down")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), ShutdownTimeout)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Printf("HTTP server shutdown failed: %v", err)
+	}
+
+	// interrupted jobs are released to the queue and continue on the next start
+	pool.Wait()
+	webhookPool.Wait()
+}
+
+// startQueue starts the key generation workers and queues requests that were left without a job.
+func startQueue(ctx context.Context, db *gorm.DB, cfg *config.Config) *queue.Pool {
+	services.SetKeyGenerationWorkers(cfg.KeyGenWorkers)
+	services.SetWebhookMaxAttempts(cfg.WebhookMaxAttempts)
+
+	queueConfig := queue.DefaultConfig()
+	queueConfig.Workers = cfg.JobWorkers
+
+	pool := queue.NewPool(db, queueConfig)
+	pool.Register(services.ValidatorRequestJobKind, services.NewValidatorRequestJob(db))
+
+	recovered, err := services.RecoverValidatorRequests(db, cfg.JobMaxAttempts)
+	if err != nil {
+		log.Fatalf("%s: %v", services.ErrRecoveringValidatorRequests, err)
+	}
+	if recovered > 0 {
+		log.Printf("Queued %d validator requests without a job", recovered)
+	}
+
+	pool.Start(ctx)
+
+	return pool
+}
+
+// startWebhookQueue starts the webhook delivery workers, separate from the key generation workers.
+func startWebhookQueue(ctx context.Context, db *gorm.DB, cfg *config.Config) *queue.Pool {
+	queueConfig := queue.DefaultConfig()
+	queueConfig.Workers = cfg.WebhookWorkers
+
+	pool := queue.NewPool(db, queueConfig)
+	pool.Register(services.WebhookJobKind, services.NewWebhookJob(db, cfg.WebhookSecret, cfg.WebhookTimeout, cfg.WebhookAllowPrivateNetworks))
+	pool.Start(ctx)
+
+	return pool
+}
+
+// pruneIdempotencyKeys periodically deletes idempotency keys whose retention window passed.
+func pruneIdempotencyKeys(ctx context.Context, db *gorm.DB) {
+	ticker := time.NewTicker(IdempotencyKeyPruneInterval)
+	defer ticker.Stop()
+
+	for {
+		deleted, err := repository.DeleteExpiredIdempotencyKeys(db, time.Now())
+		if err != nil {
+			log.Printf("Failed to delete expired idempotency keys: %v", err)
+		} else if deleted > 0 {
+			log.Printf("Deleted %d expired idempotency keys", deleted)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func setupKeyring(cfg *config.Config) *repository.Keyring {
+	if cfg.MasterKey == nil {
+		log.Fatal(config.ErrMasterKeyNotConfigured)
+	}
 
-	err = ginEngine.Run(":8080")
+	var previousMasterKeys [][]byte
+	if cfg.PreviousMasterKey != nil {
+		previousMasterKeys = append(previousMasterKeys, cfg.PreviousMasterKey)
+	}
+
+	keyring, err := repository.NewKeyring(cfg.MasterKey, previousMasterKeys...)
 	if err != nil {
 		log.Fatal(err)
 	}
+	repository.SetKeyring(keyring)
+
+	return keyring
+}
+
+func generateMasterKey() {
+	key := make([]byte, repository.MasterKeyLength)
+	if _, err := rand.Read(key); err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println(base64.StdEncoding.EncodeToString(key))
+}
+
+func rotateMasterKey(db *gorm.DB, keyring *repository.Keyring) {
+	updated, err := repository.RotateMasterKey(db, keyring)
+	if err != nil {
+		log.Fatalf("Master key rotation failed after %d values: %v", updated, err)
+	}
+
+	fmt.Printf("Rewrapped %d values with master key %s\n", updated, keyring.PrimaryKeyID())
+}
+
+func migrate(db *gorm.DB, args []string) {
+	if len(args) == 0 {
+		log.Fatalf("Usage: validator-service migrate %s|%s [steps]|%s", MigrateUp, MigrateDown, MigrateStatus)
+	}
+
+	switch args[0] {
+	case MigrateUp:
+		migrateUp(db)
+	case MigrateDown:
+		steps := 1
+		if len(args) > 1 {
+			var err error
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				log.Fatalf("Invalid number of steps: %s", args[1])
+			}
+		}
+		migrateDown(db, steps)
+	case MigrateStatus:
+		migrateStatus(db)
+	default:
+		log.Fatalf("Unknown migrate command: %s", args[0])
+	}
+}
+
+func migrateUp(db *gorm.DB) {
+	applied, err := migrations.Up(db)
+	for _, migration := range applied {
+		log.Printf("Applied migration %04d_%s", migration.Version, migration.Name)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func migrateDown(db *gorm.DB, steps int) {
+	rolledBack, err := migrations.Down(db, steps)
+	for _, migration := range rolledBack {
+		log.Printf("Rolled back migration %04d_%s", migration.Version, migration.Name)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func migrateStatus(db *gorm.DB) {
+	statuses, err := migrations.GetStatus(db)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for _, status := range statuses {
+		appliedAt := "pending"
+		if status.Applied {
+			appliedAt = "applied " + status.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		fmt.Printf("%04d_%s\t%s\n", status.Version, status.Name, appliedAt)
+	}
+}
+
+func manageTenants(db *gorm.DB, args []string) {
+	usage := fmt.Sprintf("Usage: validator-service tenant %s <name>|%s <tenant id> <role>|%s <key prefix>|%s <tenant id>",
+		TenantCreate, TenantAddKey, TenantRevokeKey, TenantAdopt)
+	argCount := 2
+	if len(args) > 0 && args[0] == TenantAddKey {
+		argCount = 3
+	}
+	if len(args) != argCount {
+		log.Fatal(usage)
+	}
+
+	switch args[0] {
+	case TenantCreate:
+		tenant, key, err := services.CreateTenant(db, args[1])
+		if err != nil {
+			log.Fatalf("Failed to create tenant: %v", err)
+		}
+		fmt.Printf("Created tenant %d\nAPI key (%s): %s\n", tenant.ID, models.RoleAdmin, key)
+	case TenantAddKey:
+		apiKey, key, err := services.CreateAPIKey(db, parseTenantID(args[1]), models.Role(args[2]))
+		if err != nil {
+			log.Fatalf("Failed to create API key: %v", err)
+		}
+		fmt.Printf("API key (%s): %s\n", apiKey.Role, key)
+	case TenantRevokeKey:
+		revoked, err := repository.RevokeAPIKey(db, args[1])
+		if err != nil {
+			log.Fatalf("Failed to revoke API key: %v", err)
+		}
+		if !revoked {
+			log.Fatalf("Unknown API key: %s", args[1])
+		}
+		fmt.Printf("Revoked API key %s\n", args[1])
+	case TenantAdopt:
+		tenantID := parseTenantID(args[1])
+		if _, err := repository.GetTenantByID(db, tenantID); err != nil {
+			log.Fatalf("Unknown tenant %d: %v", tenantID, err)
+		}
+		adopted, err := repository.AdoptValidatorRequests(db, tenantID)
+		if err != nil {
+			log.Fatalf("Failed to adopt validator requests: %v", err)
+		}
+		fmt.Printf("Moved %d validator requests without tenant to tenant %d\n", adopted, tenantID)
+	default:
+		log.Fatal(usage)
+	}
+}
+
+func parseTenantID(value string) uint {
+	tenantID, err := strconv.ParseUint(value, 10, 64)
+	if err != nil || tenantID == 0 {
+		log.Fatalf("Invalid tenant id: %s", value)
+	}
+
+	return uint(tenantID)
 }
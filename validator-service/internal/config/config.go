@@ -0,0 +1,233 @@
+package config
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"validator-service/internal/eth2"
+)
+
+const (
+	DefaultNetwork  = eth2.NetworkMainnet
+	DefaultDBDriver = "sqlite"
+	DefaultDBDSN    = "validators.db"
+
+	DefaultJobWorkers              = 4
+	DefaultJobMaxAttempts          = 5
+	DefaultMaxValidatorsPerRequest = 1000
+	DefaultMaxQueueDepth           = 100
+	DefaultIdempotencyKeyTTL       = 24 * time.Hour
+	DefaultWebhookWorkers          = 4
+	DefaultWebhookMaxAttempts      = 10
+	DefaultWebhookTimeout          = 10 * time.Second
+)
+
+var ErrMasterKeyNotConfigured = errors.New("master key is not configured, set MASTER_KEY or MASTER_KEY_FILE")
+
+// Config is the service configuration read from environment variables.
+type Config struct {
+	DBDriver string
+	DBDSN    string
+	Network  *eth2.Network
+	// MasterKey wraps the data keys that encrypt validator secrets at rest. It is required
+	// by every command that reads or writes secrets.
+	MasterKey []byte
+	// PreviousMasterKey is only used to unwrap data keys while rotating to a new MasterKey.
+	PreviousMasterKey []byte
+	// JobWorkers is the number of queued jobs processed concurrently by this replica.
+	JobWorkers int
+	// JobMaxAttempts is the number of attempts after which a failing job is marked dead.
+	JobMaxAttempts uint
+	// KeyGenWorkers is the number of keys derived concurrently across all requests.
+	KeyGenWorkers int
+	// MaxValidatorsPerRequest limits the number of validators of a single request.
+	MaxValidatorsPerRequest uint
+	// MaxQueueDepth is the number of pending and running validator request jobs above which new requests are rejected.
+	MaxQueueDepth int
+	// IdempotencyKeyTTL is how long responses are replayed for retries with the same Idempotency-Key.
+	IdempotencyKeyTTL time.Duration
+	// WebhookSecret signs the webhooks sent to callback URLs. Requests with a callback URL are
+	// rejected while it is not configured.
+	WebhookSecret string
+	// WebhookWorkers is the number of webhooks delivered concurrently by this replica. Webhooks have their
+	// own workers, so that slow callback URLs do not hold up key generation.
+	WebhookWorkers int
+	// WebhookMaxAttempts is the number of attempts to deliver a webhook before it is given up.
+	WebhookMaxAttempts uint
+	// WebhookTimeout limits a single webhook delivery attempt.
+	WebhookTimeout time.Duration
+	// WebhookAllowPrivateNetworks allows callback URLs on loopback, private and link-local addresses,
+	// which are refused by default so that tenants cannot reach internal services through webhooks.
+	WebhookAllowPrivateNetworks bool
+}
+
+// LoadConfig loads the service configuration from the environment.
+func LoadConfig() (*Config, error) {
+	networkName := getEnv("NETWORK", DefaultNetwork)
+	network, err := eth2.GetNetwork(networkName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load network '%s': %w", networkName, err)
+	}
+
+	masterKey, err := loadKey("MASTER_KEY")
+	if err != nil {
+		return nil, err
+	}
+
+	previousMasterKey, err := loadKey("PREVIOUS_MASTER_KEY")
+	if err != nil {
+		return nil, err
+	}
+
+	jobWorkers, err := getEnvInt("JOB_WORKERS", DefaultJobWorkers)
+	if err != nil {
+		return nil, err
+	}
+
+	jobMaxAttempts, err := getEnvInt("JOB_MAX_ATTEMPTS", DefaultJobMaxAttempts)
+	if err != nil {
+		return nil, err
+	}
+
+	keyGenWorkers, err := getEnvInt("KEYGEN_WORKERS", runtime.NumCPU())
+	if err != nil {
+		return nil, err
+	}
+
+	maxValidatorsPerRequest, err := getEnvInt("MAX_VALIDATORS_PER_REQUEST", DefaultMaxValidatorsPerRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	maxQueueDepth, err := getEnvInt("MAX_QUEUE_DEPTH", DefaultMaxQueueDepth)
+	if err != nil {
+		return nil, err
+	}
+
+	idempotencyKeyTTL, err := getEnvDuration("IDEMPOTENCY_KEY_TTL", DefaultIdempotencyKeyTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	webhookWorkers, err := getEnvInt("WEBHOOK_WORKERS", DefaultWebhookWorkers)
+	if err != nil {
+		return nil, err
+	}
+
+	webhookMaxAttempts, err := getEnvInt("WEBHOOK_MAX_ATTEMPTS", DefaultWebhookMaxAttempts)
+	if err != nil {
+		return nil, err
+	}
+
+	webhookTimeout, err := getEnvDuration("WEBHOOK_TIMEOUT", DefaultWebhookTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	webhookAllowPrivateNetworks, err := getEnvBool("WEBHOOK_ALLOW_PRIVATE_NETWORKS", false)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Config{
+		DBDriver:                    getEnv("DB_DRIVER", DefaultDBDriver),
+		DBDSN:                       getEnv("DB_DSN", DefaultDBDSN),
+		Network:                     network,
+		MasterKey:                   masterKey,
+		PreviousMasterKey:           previousMasterKey,
+		JobWorkers:                  jobWorkers,
+		JobMaxAttempts:              uint(jobMaxAttempts),
+		KeyGenWorkers:               keyGenWorkers,
+		MaxValidatorsPerRequest:     uint(maxValidatorsPerRequest),
+		MaxQueueDepth:               maxQueueDepth,
+		IdempotencyKeyTTL:           idempotencyKeyTTL,
+		WebhookSecret:               getEnv("WEBHOOK_SECRET", ""),
+		WebhookWorkers:              webhookWorkers,
+		WebhookMaxAttempts:          uint(webhookMaxAttempts),
+		WebhookTimeout:              webhookTimeout,
+		WebhookAllowPrivateNetworks: webhookAllowPrivateNetworks,
+	}, nil
+}
+
+// loadKey reads a base64 encoded key from the variable name or from the file named by name_FILE.
+func loadKey(name string) ([]byte, error) {
+	encoded := getEnv(name, "")
+
+	if file := getEnv(name+"_FILE", ""); file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s '%s': %w", name+"_FILE", file, err)
+		}
+		encoded = string(data)
+	}
+
+	if encoded == "" {
+		return nil, nil
+	}
+
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", name, err)
+	}
+
+	return key, nil
+}
+
+func getEnv(name string, fallback string) string {
+	if value, ok := os.LookupEnv(name); ok && value != "" {
+		return value
+	}
+
+	return fallback
+}
+
+// getEnvInt reads a positive integer from the variable name.
+func getEnvInt(name string, fallback int) (int, error) {
+	value := getEnv(name, "")
+	if value == "" {
+		return fallback, nil
+	}
+
+	number, err := strconv.Atoi(value)
+	if err != nil || number < 1 {
+		return 0, fmt.Errorf("invalid %s '%s', expected a positive integer", name, value)
+	}
+
+	return number, nil
+}
+
+// getEnvDuration reads a positive duration such as 24h from the variable name.
+func getEnvDuration(name string, fallback time.Duration) (time.Duration, error) {
+	value := getEnv(name, "")
+	if value == "" {
+		return fallback, nil
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		return 0, fmt.Errorf("invalid %s '%s', expected a positive duration such as 24h", name, value)
+	}
+
+	return duration, nil
+}
+
+// getEnvBool reads a boolean such as true or false from the variable name.
+func getEnvBool(name string, fallback bool) (bool, error) {
+	value := getEnv(name, "")
+	if value == "" {
+		return fallback, nil
+	}
+
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s '%s', expected true or false", name, value)
+	}
+
+	return enabled, nil
+}
@@ -0,0 +1,83 @@
+package eth2
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	"validator-service/internal/keys"
+)
+
+const (
+	// MaxEffectiveBalanceGwei is the deposit amount of a single validator, 32 ETH.
+	MaxEffectiveBalanceGwei uint64 = 32_000_000_000
+
+	BLSWithdrawalPrefix              byte = 0x00
+	ExecutionAddressWithdrawalPrefix byte = 0x01
+
+	// DepositCLIVersion is reported in deposit_data files. The staking launchpad
+	// refuses files produced by deposit cli versions older than this one.
+	DepositCLIVersion = "2.7.0"
+)
+
+// DepositData is a single entry of a staking-deposit-cli deposit_data-*.json file.
+type DepositData struct {
+	Pubkey                string `json:"pubkey"`
+	WithdrawalCredentials string `json:"withdrawal_credentials"`
+	Amount                uint64 `json:"amount"`
+	Signature             string `json:"signature"`
+	DepositMessageRoot    string `json:"deposit_message_root"`
+	DepositDataRoot       string `json:"deposit_data_root"`
+	ForkVersion           string `json:"fork_version"`
+	NetworkName           string `json:"network_name"`
+	DepositCLIVersion     string `json:"deposit_cli_version"`
+}
+
+// BLSWithdrawalCredentials returns 0x00 withdrawal credentials for a BLS withdrawal public key.
+func BLSWithdrawalCredentials(withdrawalPubkey []byte) [32]byte {
+	credentials := sha256.Sum256(withdrawalPubkey)
+	credentials[0] = BLSWithdrawalPrefix
+	return credentials
+}
+
+// ExecutionWithdrawalCredentials returns 0x01 withdrawal credentials for a 20-byte execution address.
+func ExecutionWithdrawalCredentials(address [20]byte) [32]byte {
+	var credentials [32]byte
+	credentials[0] = ExecutionAddressWithdrawalPrefix
+	copy(credentials[12:], address[:])
+	return credentials
+}
+
+// NewDepositData signs a deposit of amount gwei for the keypair on the given network.
+func NewDepositData(keypair *keys.Keypair, withdrawalCredentials [32]byte, amount uint64, network *Network) *DepositData {
+	pubkey := keypair.Public.Serialize()
+
+	depositMessageRoot := containerRoot(
+		bytesRoot(pubkey[:]),
+		withdrawalCredentials,
+		uint64Root(amount),
+	)
+
+	// deposits are valid across forks, so they are always signed with the genesis fork version
+	domain := ComputeDomain(DomainDeposit, network.GenesisForkVersion, Root{})
+	signingRoot := ComputeSigningRoot(depositMessageRoot, domain)
+	signature := keypair.Sign(signingRoot[:])
+
+	depositDataRoot := containerRoot(
+		bytesRoot(pubkey[:]),
+		withdrawalCredentials,
+		uint64Root(amount),
+		bytesRoot(signature[:]),
+	)
+
+	return &DepositData{
+		Pubkey:                hex.EncodeToString(pubkey[:]),
+		WithdrawalCredentials: hex.EncodeToString(withdrawalCredentials[:]),
+		Amount:                amount,
+		Signature:             hex.EncodeToString(signature[:]),
+		DepositMessageRoot:    hex.EncodeToString(depositMessageRoot[:]),
+		DepositDataRoot:       hex.EncodeToString(depositDataRoot[:]),
+		ForkVersion:           hex.EncodeToString(network.GenesisForkVersion[:]),
+		NetworkName:           network.Name,
+		DepositCLIVersion:     DepositCLIVersion,
+	}
+}
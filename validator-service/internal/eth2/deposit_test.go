@@ -0,0 +1,50 @@
+package eth2
+
+import (
+	"encoding/hex"
+	"testing"
+
+	blsu "github.com/protolambda/bls12-381-util"
+	"github.com/stretchr/testify/assert"
+	"validator-service/internal/keys"
+)
+
+func TestComputeDomainMainnetDeposit(t *testing.T) {
+	network, err := GetNetwork(NetworkMainnet)
+	assert.NoError(t, err)
+
+	domain := ComputeDomain(DomainDeposit, network.GenesisForkVersion, Root{})
+	assert.Equal(t, "03000000f5a5fd42d16a20302798ef6ed309979b43003d2320d9f0e8ea9831a9", hex.EncodeToString(domain[:]))
+}
+
+func TestNewDepositData(t *testing.T) {
+	network, err := GetNetwork(NetworkHoodi)
+	assert.NoError(t, err)
+
+	keypair, err := keys.GenerateKeypair()
+	assert.NoError(t, err)
+
+	var address [20]byte
+	address[19] = 0x01
+	credentials := ExecutionWithdrawalCredentials(address)
+	assert.Equal(t, "0100000000000000000000000000000000000000000000000000000000000001", hex.EncodeToString(credentials[:]))
+
+	depositData := NewDepositData(keypair, credentials, MaxEffectiveBalanceGwei, network)
+	assert.Equal(t, keypair.PublicKeyHex(), "0x"+depositData.Pubkey)
+	assert.Equal(t, "10000910", depositData.ForkVersion)
+	assert.Equal(t, NetworkHoodi, depositData.NetworkName)
+
+	messageRoot, _ := hex.DecodeString(depositData.DepositMessageRoot)
+	domain := ComputeDomain(DomainDeposit, network.GenesisForkVersion, Root{})
+	signingRoot := ComputeSigningRoot(Root(messageRoot), domain)
+
+	rawSignature, _ := hex.DecodeString(depositData.Signature)
+	var signature blsu.Signature
+	assert.NoError(t, signature.Deserialize((*[96]byte)(rawSignature)))
+	assert.True(t, blsu.Verify(keypair.Public, signingRoot[:], &signature))
+}
+
+func TestBLSWithdrawalCredentials(t *testing.T) {
+	credentials := BLSWithdrawalCredentials([]byte("withdrawal pubkey"))
+	assert.Equal(t, BLSWithdrawalPrefix, credentials[0])
+}
@@ -0,0 +1,126 @@
+package eth2
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidHex = errors.New("invalid 0x prefixed hex value")
+
+// Uint64 is an uint64 that is encoded as a decimal string in JSON, like in the beacon node API.
+type Uint64 uint64
+
+func (u Uint64) MarshalJSON() ([]byte, error) {
+	return json.Marshal(strconv.FormatUint(uint64(u), 10))
+}
+
+// UnmarshalJSON accepts decimal strings and plain JSON numbers.
+func (u *Uint64) UnmarshalJSON(data []byte) error {
+	value, err := strconv.ParseUint(strings.Trim(string(data), `"`), 10, 64)
+	if err != nil {
+		return err
+	}
+
+	*u = Uint64(value)
+	return nil
+}
+
+// HexBytes are variable length bytes encoded as 0x prefixed hex.
+type HexBytes []byte
+
+func (b HexBytes) MarshalText() ([]byte, error) {
+	return encodeHex(b), nil
+}
+
+func (b *HexBytes) UnmarshalText(text []byte) error {
+	decoded, err := decodeHex(text)
+	if err != nil {
+		return err
+	}
+
+	*b = decoded
+	return nil
+}
+
+// BLSPubkey is a compressed BLS public key.
+type BLSPubkey [48]byte
+
+// BLSSignature is a compressed BLS signature.
+type BLSSignature [96]byte
+
+// ExecutionAddress is the 20-byte address of an execution layer account.
+type ExecutionAddress [20]byte
+
+func (r Root) MarshalText() ([]byte, error) {
+	return encodeHex(r[:]), nil
+}
+
+func (r *Root) UnmarshalText(text []byte) error {
+	return decodeFixedHex(text, r[:])
+}
+
+func (v Version) MarshalText() ([]byte, error) {
+	return encodeHex(v[:]), nil
+}
+
+func (v *Version) UnmarshalText(text []byte) error {
+	return decodeFixedHex(text, v[:])
+}
+
+func (p BLSPubkey) MarshalText() ([]byte, error) {
+	return encodeHex(p[:]), nil
+}
+
+func (p *BLSPubkey) UnmarshalText(text []byte) error {
+	return decodeFixedHex(text, p[:])
+}
+
+func (s BLSSignature) MarshalText() ([]byte, error) {
+	return encodeHex(s[:]), nil
+}
+
+func (s *BLSSignature) UnmarshalText(text []byte) error {
+	return decodeFixedHex(text, s[:])
+}
+
+func (a ExecutionAddress) MarshalText() ([]byte, error) {
+	return encodeHex(a[:]), nil
+}
+
+func (a *ExecutionAddress) UnmarshalText(text []byte) error {
+	return decodeFixedHex(text, a[:])
+}
+
+func encodeHex(value []byte) []byte {
+	return []byte("0x" + hex.EncodeToString(value))
+}
+
+func decodeHex(text []byte) ([]byte, error) {
+	value := string(text)
+	if !strings.HasPrefix(value, "0x") {
+		return nil, ErrInvalidHex
+	}
+
+	decoded, err := hex.DecodeString(value[2:])
+	if err != nil {
+		return nil, ErrInvalidHex
+	}
+
+	return decoded, nil
+}
+
+func decodeFixedHex(text []byte, dst []byte) error {
+	decoded, err := decodeHex(text)
+	if err != nil {
+		return err
+	}
+	if len(decoded) != len(dst) {
+		return ErrInvalidHex
+	}
+
+	copy(dst, decoded)
+	return nil
+}
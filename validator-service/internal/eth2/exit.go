@@ -0,0 +1,27 @@
+package eth2
+
+import "validator-service/internal/keys"
+
+// SignedVoluntaryExit is a voluntary exit in the format of the beacon node API, ready to be
+// submitted to /eth/v1/beacon/pool/voluntary_exits.
+type SignedVoluntaryExit struct {
+	Message   VoluntaryExit `json:"message"`
+	Signature BLSSignature  `json:"signature"`
+}
+
+// VoluntaryExitSigningRoot returns the signing root of a voluntary exit. Since Deneb exits are
+// always signed with the Capella fork version (EIP-7044), so they stay valid in later forks.
+func VoluntaryExitSigningRoot(exit *VoluntaryExit, network *Network) Root {
+	domain := ComputeDomain(DomainVoluntaryExit, network.CapellaForkVersion, network.GenesisValidatorsRoot)
+	return ComputeSigningRoot(exit.HashTreeRoot(), domain)
+}
+
+// NewSignedVoluntaryExit signs an exit of the validator with the given index on the network.
+func NewSignedVoluntaryExit(keypair *keys.Keypair, exit VoluntaryExit, network *Network) *SignedVoluntaryExit {
+	signingRoot := VoluntaryExitSigningRoot(&exit, network)
+
+	return &SignedVoluntaryExit{
+		Message:   exit,
+		Signature: keypair.Sign(signingRoot[:]),
+	}
+}
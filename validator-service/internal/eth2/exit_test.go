@@ -0,0 +1,31 @@
+package eth2
+
+import (
+	"encoding/json"
+	"testing"
+
+	blsu "github.com/protolambda/bls12-381-util"
+	"github.com/stretchr/testify/assert"
+	"validator-service/internal/keys"
+)
+
+func TestNewSignedVoluntaryExit(t *testing.T) {
+	network, err := GetNetwork(NetworkHoodi)
+	assert.NoError(t, err)
+
+	keypair, err := keys.GenerateKeypair()
+	assert.NoError(t, err)
+
+	exit := NewSignedVoluntaryExit(keypair, VoluntaryExit{Epoch: 1000, ValidatorIndex: 42}, network)
+
+	domain := ComputeDomain(DomainVoluntaryExit, Version{0x40, 0x00, 0x09, 0x10}, network.GenesisValidatorsRoot)
+	signingRoot := ComputeSigningRoot(containerRoot(uint64Root(1000), uint64Root(42)), domain)
+
+	var signature blsu.Signature
+	assert.NoError(t, signature.Deserialize((*[96]byte)(&exit.Signature)))
+	assert.True(t, blsu.Verify(keypair.Public, signingRoot[:], &signature))
+
+	encoded, err := json.Marshal(exit)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"message": {"epoch": "1000", "validator_index": "42"}, "signature": "`+string(encodeHex(exit.Signature[:]))+`"}`, string(encoded))
+}
@@ -0,0 +1,204 @@
+package eth2
+
+const (
+	SlotsPerEpoch = 32
+
+	// MaxValidatorsPerCommittee and MaxCommitteesPerSlot limit the aggregation bits of attestations.
+	MaxValidatorsPerCommittee = 2048
+	MaxCommitteesPerSlot      = 64
+)
+
+// SyncCommitteeBits is the aggregation bitvector of a sync subcommittee of 128 validators.
+type SyncCommitteeBits [16]byte
+
+// CommitteeBits is the bitvector of the committees included in an attestation since Electra.
+type CommitteeBits [8]byte
+
+func (b SyncCommitteeBits) MarshalText() ([]byte, error) {
+	return encodeHex(b[:]), nil
+}
+
+func (b *SyncCommitteeBits) UnmarshalText(text []byte) error {
+	return decodeFixedHex(text, b[:])
+}
+
+func (b CommitteeBits) MarshalText() ([]byte, error) {
+	return encodeHex(b[:]), nil
+}
+
+func (b *CommitteeBits) UnmarshalText(text []byte) error {
+	return decodeFixedHex(text, b[:])
+}
+
+// ComputeEpochAtSlot returns the epoch of a slot.
+func ComputeEpochAtSlot(slot Uint64) Uint64 {
+	return slot / SlotsPerEpoch
+}
+
+type Checkpoint struct {
+	Epoch Uint64 `json:"epoch"`
+	Root  Root   `json:"root"`
+}
+
+func (c *Checkpoint) HashTreeRoot() Root {
+	return containerRoot(uint64Root(uint64(c.Epoch)), c.Root)
+}
+
+type AttestationData struct {
+	Slot            Uint64     `json:"slot"`
+	Index           Uint64     `json:"index"`
+	BeaconBlockRoot Root       `json:"beacon_block_root"`
+	Source          Checkpoint `json:"source"`
+	Target          Checkpoint `json:"target"`
+}
+
+func (a *AttestationData) HashTreeRoot() Root {
+	return containerRoot(
+		uint64Root(uint64(a.Slot)),
+		uint64Root(uint64(a.Index)),
+		a.BeaconBlockRoot,
+		a.Source.HashTreeRoot(),
+		a.Target.HashTreeRoot(),
+	)
+}
+
+// Attestation is an aggregated attestation. CommitteeBits are only set since Electra.
+type Attestation struct {
+	AggregationBits HexBytes        `json:"aggregation_bits"`
+	Data            AttestationData `json:"data"`
+	Signature       BLSSignature    `json:"signature"`
+	CommitteeBits   *CommitteeBits  `json:"committee_bits,omitempty"`
+}
+
+// HashTreeRoot returns the root of the attestation, in the Electra layout if electra is set.
+func (a *Attestation) HashTreeRoot(electra bool) (Root, error) {
+	if !electra {
+		aggregationBitsRoot, err := bitlistRoot(a.AggregationBits, MaxValidatorsPerCommittee)
+		if err != nil {
+			return Root{}, err
+		}
+
+		return containerRoot(aggregationBitsRoot, a.Data.HashTreeRoot(), bytesRoot(a.Signature[:])), nil
+	}
+
+	if a.CommitteeBits == nil {
+		return Root{}, ErrMissingCommitteeBits
+	}
+
+	aggregationBitsRoot, err := bitlistRoot(a.AggregationBits, MaxValidatorsPerCommittee*MaxCommitteesPerSlot)
+	if err != nil {
+		return Root{}, err
+	}
+
+	return containerRoot(
+		aggregationBitsRoot,
+		a.Data.HashTreeRoot(),
+		bytesRoot(a.Signature[:]),
+		bytesRoot(a.CommitteeBits[:]),
+	), nil
+}
+
+type AggregateAndProof struct {
+	AggregatorIndex Uint64       `json:"aggregator_index"`
+	Aggregate       Attestation  `json:"aggregate"`
+	SelectionProof  BLSSignature `json:"selection_proof"`
+}
+
+func (a *AggregateAndProof) HashTreeRoot(electra bool) (Root, error) {
+	aggregateRoot, err := a.Aggregate.HashTreeRoot(electra)
+	if err != nil {
+		return Root{}, err
+	}
+
+	return containerRoot(
+		uint64Root(uint64(a.AggregatorIndex)),
+		aggregateRoot,
+		bytesRoot(a.SelectionProof[:]),
+	), nil
+}
+
+type BeaconBlockHeader struct {
+	Slot          Uint64 `json:"slot"`
+	ProposerIndex Uint64 `json:"proposer_index"`
+	ParentRoot    Root   `json:"parent_root"`
+	StateRoot     Root   `json:"state_root"`
+	BodyRoot      Root   `json:"body_root"`
+}
+
+// HashTreeRoot returns the root of the header, which equals the root of the block it summarizes.
+func (h *BeaconBlockHeader) HashTreeRoot() Root {
+	return containerRoot(
+		uint64Root(uint64(h.Slot)),
+		uint64Root(uint64(h.ProposerIndex)),
+		h.ParentRoot,
+		h.StateRoot,
+		h.BodyRoot,
+	)
+}
+
+type VoluntaryExit struct {
+	Epoch          Uint64 `json:"epoch"`
+	ValidatorIndex Uint64 `json:"validator_index"`
+}
+
+func (e *VoluntaryExit) HashTreeRoot() Root {
+	return containerRoot(uint64Root(uint64(e.Epoch)), uint64Root(uint64(e.ValidatorIndex)))
+}
+
+type SyncAggregatorSelectionData struct {
+	Slot              Uint64 `json:"slot"`
+	SubcommitteeIndex Uint64 `json:"subcommittee_index"`
+}
+
+func (s *SyncAggregatorSelectionData) HashTreeRoot() Root {
+	return containerRoot(uint64Root(uint64(s.Slot)), uint64Root(uint64(s.SubcommitteeIndex)))
+}
+
+type SyncCommitteeContribution struct {
+	Slot              Uint64            `json:"slot"`
+	BeaconBlockRoot   Root              `json:"beacon_block_root"`
+	SubcommitteeIndex Uint64            `json:"subcommittee_index"`
+	AggregationBits   SyncCommitteeBits `json:"aggregation_bits"`
+	Signature         BLSSignature      `json:"signature"`
+}
+
+func (s *SyncCommitteeContribution) HashTreeRoot() Root {
+	return containerRoot(
+		uint64Root(uint64(s.Slot)),
+		s.BeaconBlockRoot,
+		uint64Root(uint64(s.SubcommitteeIndex)),
+		bytesRoot(s.AggregationBits[:]),
+		bytesRoot(s.Signature[:]),
+	)
+}
+
+type ContributionAndProof struct {
+	AggregatorIndex Uint64                    `json:"aggregator_index"`
+	Contribution    SyncCommitteeContribution `json:"contribution"`
+	SelectionProof  BLSSignature              `json:"selection_proof"`
+}
+
+func (c *ContributionAndProof) HashTreeRoot() Root {
+	return containerRoot(
+		uint64Root(uint64(c.AggregatorIndex)),
+		c.Contribution.HashTreeRoot(),
+		bytesRoot(c.SelectionProof[:]),
+	)
+}
+
+// ValidatorRegistration registers the fee recipient and gas limit of a validator with block builders.
+type ValidatorRegistration struct {
+	FeeRecipient ExecutionAddress `json:"fee_recipient"`
+	GasLimit     Uint64           `json:"gas_limit"`
+	Timestamp    Uint64           `json:"timestamp"`
+	Pubkey       BLSPubkey        `json:"pubkey"`
+}
+
+func (v *ValidatorRegistration) HashTreeRoot() Root {
+	return containerRoot(
+		bytesRoot(v.FeeRecipient[:]),
+		uint64Root(uint64(v.GasLimit)),
+		uint64Root(uint64(v.Timestamp)),
+		bytesRoot(v.Pubkey[:]),
+	)
+}
@@ -0,0 +1,70 @@
+package eth2
+
+import (
+	"encoding/hex"
+	"errors"
+)
+
+const (
+	NetworkMainnet = "mainnet"
+	NetworkSepolia = "sepolia"
+	NetworkHolesky = "holesky"
+	NetworkHoodi   = "hoodi"
+)
+
+var ErrUnknownNetwork = errors.New("unknown network")
+
+// Network holds the chain parameters needed to sign messages for a beacon chain.
+type Network struct {
+	Name               string
+	GenesisForkVersion Version
+	// GenesisValidatorsRoot identifies the chain in signatures and slashing protection data.
+	GenesisValidatorsRoot Root
+	// CapellaForkVersion signs voluntary exits, which are fixed to the Capella fork since Deneb (EIP-7044).
+	CapellaForkVersion Version
+}
+
+var networks = map[string]*Network{
+	NetworkMainnet: {
+		Name:                  NetworkMainnet,
+		GenesisForkVersion:    Version{0x00, 0x00, 0x00, 0x00},
+		GenesisValidatorsRoot: mustDecodeRoot("4b363db94e286120d76eb905340fdd4e54bfe9f06bf33ff6cf5ad27f511bfe95"),
+		CapellaForkVersion:    Version{0x03, 0x00, 0x00, 0x00},
+	},
+	NetworkSepolia: {
+		Name:                  NetworkSepolia,
+		GenesisForkVersion:    Version{0x90, 0x00, 0x00, 0x69},
+		GenesisValidatorsRoot: mustDecodeRoot("d8ea171f3c94aea21ebc42a1ed61052acf3f9209c00e4efbaaddac09ed9b8078"),
+		CapellaForkVersion:    Version{0x90, 0x00, 0x00, 0x72},
+	},
+	NetworkHolesky: {
+		Name:                  NetworkHolesky,
+		GenesisForkVersion:    Version{0x01, 0x01, 0x70, 0x00},
+		GenesisValidatorsRoot: mustDecodeRoot("9143aa7c615a7f7115e2b6aac319c03529df8242ae705fba9df39b79c59fa8b1"),
+		CapellaForkVersion:    Version{0x04, 0x01, 0x70, 0x00},
+	},
+	NetworkHoodi: {
+		Name:                  NetworkHoodi,
+		GenesisForkVersion:    Version{0x10, 0x00, 0x09, 0x10},
+		GenesisValidatorsRoot: mustDecodeRoot("212f13fc4df078b6cb7db228f1c8307566dcecf900867401a92023d7ba99cb5f"),
+		CapellaForkVersion:    Version{0x40, 0x00, 0x09, 0x10},
+	},
+}
+
+// GetNetwork returns the parameters of a known network by name.
+func GetNetwork(name string) (*Network, error) {
+	network, ok := networks[name]
+	if !ok {
+		return nil, ErrUnknownNetwork
+	}
+
+	return network, nil
+}
+
+func mustDecodeRoot(value string) Root {
+	var root Root
+	if _, err := hex.Decode(root[:], []byte(value)); err != nil {
+		panic(err)
+	}
+	return root
+}
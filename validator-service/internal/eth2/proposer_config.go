@@ -0,0 +1,53 @@
+package eth2
+
+import "errors"
+
+const (
+	ProposerConfigTeku     = "teku"
+	ProposerConfigLodestar = "lodestar"
+	ProposerConfigPrysm    = "prysm"
+)
+
+var ErrUnknownProposerConfigFormat = errors.New("unknown proposer config format")
+
+// proposerConfigFileNames are the file names the validator clients document for their proposer config.
+var proposerConfigFileNames = map[string]string{
+	ProposerConfigTeku:     "proposer_config.json",
+	ProposerConfigLodestar: "proposer_config.json",
+	ProposerConfigPrysm:    "proposer-settings.json",
+}
+
+// ProposerOptions are the proposer options of a single validator or the default options.
+type ProposerOptions struct {
+	FeeRecipient string `json:"fee_recipient"`
+}
+
+// ProposerConfig is the proposer configuration file read by validator clients, Teku and Lodestar
+// with --validators-proposer-config and --proposerSettingsFile, Prysm with --proposer-settings-file.
+// All of them use the same schema for fee recipients.
+type ProposerConfig struct {
+	ProposerConfig map[string]ProposerOptions `json:"proposer_config"`
+	DefaultConfig  ProposerOptions            `json:"default_config"`
+}
+
+// NewProposerConfig returns a proposer config without validators that uses defaultFeeRecipient.
+func NewProposerConfig(defaultFeeRecipient string) *ProposerConfig {
+	return &ProposerConfig{
+		ProposerConfig: make(map[string]ProposerOptions),
+		DefaultConfig:  ProposerOptions{FeeRecipient: defaultFeeRecipient},
+	}
+}
+
+// SetFeeRecipient sets the fee recipient of the validator with the 0x prefixed public key.
+func (pc *ProposerConfig) SetFeeRecipient(pubkey string, feeRecipient string) {
+	pc.ProposerConfig[pubkey] = ProposerOptions{FeeRecipient: feeRecipient}
+}
+
+// ProposerConfigFileName returns the file name of the proposer config of a validator client.
+func ProposerConfigFileName(format string) (string, error) {
+	fileName, ok := proposerConfigFileNames[format]
+	if !ok {
+		return "", ErrUnknownProposerConfigFormat
+	}
+	return fileName, nil
+}
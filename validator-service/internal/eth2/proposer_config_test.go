@@ -0,0 +1,37 @@
+package eth2
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProposerConfigJSON(t *testing.T) {
+	pubkey := "0x86cc19bcf57bda80da6d3552cee23d8144e938bafeee4656a4854248808fc4efcfaed285cb865f6da94671a68e43099a"
+
+	config := NewProposerConfig("0x1234567890123456789012345678901234567890")
+	config.SetFeeRecipient(pubkey, "0x2222222222222222222222222222222222222222")
+
+	encoded, err := json.Marshal(config)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"proposer_config": {
+			"`+pubkey+`": {"fee_recipient": "0x2222222222222222222222222222222222222222"}
+		},
+		"default_config": {"fee_recipient": "0x1234567890123456789012345678901234567890"}
+	}`, string(encoded))
+}
+
+func TestProposerConfigFileName(t *testing.T) {
+	fileName, err := ProposerConfigFileName(ProposerConfigPrysm)
+	assert.NoError(t, err)
+	assert.Equal(t, "proposer-settings.json", fileName)
+
+	fileName, err = ProposerConfigFileName(ProposerConfigTeku)
+	assert.NoError(t, err)
+	assert.Equal(t, "proposer_config.json", fileName)
+
+	_, err = ProposerConfigFileName("lighthouse")
+	assert.ErrorIs(t, err, ErrUnknownProposerConfigFormat)
+}
@@ -0,0 +1,43 @@
+package eth2
+
+// DomainType is the 4-byte domain type of a signed message.
+type DomainType [4]byte
+
+// Version is a 4-byte fork version.
+type Version [4]byte
+
+// Domain is the 32-byte signature domain mixed into every signing root.
+type Domain [32]byte
+
+var (
+	DomainBeaconProposer              = DomainType{0x00, 0x00, 0x00, 0x00}
+	DomainBeaconAttester              = DomainType{0x01, 0x00, 0x00, 0x00}
+	DomainRandao                      = DomainType{0x02, 0x00, 0x00, 0x00}
+	DomainDeposit                     = DomainType{0x03, 0x00, 0x00, 0x00}
+	DomainVoluntaryExit               = DomainType{0x04, 0x00, 0x00, 0x00}
+	DomainSelectionProof              = DomainType{0x05, 0x00, 0x00, 0x00}
+	DomainAggregateAndProof           = DomainType{0x06, 0x00, 0x00, 0x00}
+	DomainSyncCommittee               = DomainType{0x07, 0x00, 0x00, 0x00}
+	DomainSyncCommitteeSelectionProof = DomainType{0x08, 0x00, 0x00, 0x00}
+	DomainContributionAndProof        = DomainType{0x09, 0x00, 0x00, 0x00}
+	DomainBLSToExecutionChange        = DomainType{0x0a, 0x00, 0x00, 0x00}
+	DomainApplicationBuilder          = DomainType{0x00, 0x00, 0x00, 0x01}
+)
+
+// ComputeDomain returns the signature domain for the domain type, fork version and genesis validators root.
+func ComputeDomain(domainType DomainType, forkVersion Version, genesisValidatorsRoot Root) Domain {
+	forkDataRoot := containerRoot(
+		bytesRoot(forkVersion[:]),
+		genesisValidatorsRoot,
+	)
+
+	var domain Domain
+	copy(domain[:4], domainType[:])
+	copy(domain[4:], forkDataRoot[:28])
+	return domain
+}
+
+// ComputeSigningRoot returns the root that is signed for an object with the given hash tree root.
+func ComputeSigningRoot(objectRoot Root, domain Domain) Root {
+	return containerRoot(objectRoot, Root(domain))
+}
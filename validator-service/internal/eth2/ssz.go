@@ -0,0 +1,115 @@
+package eth2
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+)
+
+var ErrInvalidBitlist = errors.New("invalid bitlist")
+
+// Root is a 32-byte SSZ hash tree root.
+type Root [32]byte
+
+// bytesRoot returns the hash tree root of a fixed size byte vector.
+func bytesRoot(value []byte) Root {
+	return merkleize(pack(value))
+}
+
+// uint64Root returns the hash tree root of an uint64.
+func uint64Root(value uint64) Root {
+	var root Root
+	binary.LittleEndian.PutUint64(root[:8], value)
+	return root
+}
+
+// containerRoot returns the hash tree root of a container from the roots of its fields.
+func containerRoot(fields ...Root) Root {
+	return merkleize(fields)
+}
+
+// bitlistRoot returns the hash tree root of an SSZ encoded bitlist with the given maximum number of bits.
+func bitlistRoot(encoded []byte, limit uint64) (Root, error) {
+	if len(encoded) == 0 || encoded[len(encoded)-1] == 0 {
+		return Root{}, ErrInvalidBitlist
+	}
+
+	// the highest set bit of the last byte marks the length and is not part of the bits
+	last := encoded[len(encoded)-1]
+	delimiter := 7
+	for last&(1<<delimiter) == 0 {
+		delimiter--
+	}
+
+	length := uint64(len(encoded)-1)*8 + uint64(delimiter)
+	if length > limit {
+		return Root{}, ErrInvalidBitlist
+	}
+
+	bits := append([]byte{}, encoded...)
+	bits[len(bits)-1] &^= 1 << delimiter
+	if length%8 == 0 {
+		bits = bits[:len(bits)-1]
+	}
+
+	return mixInLength(merkleizeWithLimit(pack(bits), (limit+255)/256), length), nil
+}
+
+// mixInLength returns the hash tree root of a list from the root of its elements and its length.
+func mixInLength(root Root, length uint64) Root {
+	return hashPair(root, uint64Root(length))
+}
+
+// merkleizeWithLimit merkleizes chunks padded with zero chunks to the limit of the list.
+func merkleizeWithLimit(chunks []Root, limit uint64) Root {
+	if limit == 0 {
+		return Root{}
+	}
+
+	padded := make([]Root, limit)
+	copy(padded, chunks)
+	return merkleize(padded)
+}
+
+func pack(value []byte) []Root {
+	chunks := make([]Root, (len(value)+31)/32)
+	for i := range chunks {
+		copy(chunks[i][:], value[i*32:])
+	}
+
+	return chunks
+}
+
+func merkleize(chunks []Root) Root {
+	if len(chunks) == 0 {
+		return Root{}
+	}
+
+	width := 1
+	for width < len(chunks) {
+		width *= 2
+	}
+
+	layer := make([]Root, width)
+	copy(layer, chunks)
+
+	for len(layer) > 1 {
+		next := make([]Root, len(layer)/2)
+		for i := range next {
+			next[i] = hashPair(layer[2*i], layer[2*i+1])
+		}
+		layer = next
+	}
+
+	return layer[0]
+}
+
+func hashPair(left Root, right Root) Root {
+	hasher := sha256.New()
+	hasher.Write(left[:])
+	hasher.Write(right[:])
+
+	var root Root
+	copy(root[:], hasher.Sum(nil))
+	return root
+}
@@ -0,0 +1,274 @@
+package eth2
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// SignType is the type of the message of a Web3Signer eth2 signing request.
+type SignType string
+
+const (
+	SignTypeBlockV2                           SignType = "BLOCK_V2"
+	SignTypeAttestation                       SignType = "ATTESTATION"
+	SignTypeAggregationSlot                   SignType = "AGGREGATION_SLOT"
+	SignTypeAggregateAndProof                 SignType = "AGGREGATE_AND_PROOF"
+	SignTypeAggregateAndProofV2               SignType = "AGGREGATE_AND_PROOF_V2"
+	SignTypeRandaoReveal                      SignType = "RANDAO_REVEAL"
+	SignTypeVoluntaryExit                     SignType = "VOLUNTARY_EXIT"
+	SignTypeSyncCommitteeMessage              SignType = "SYNC_COMMITTEE_MESSAGE"
+	SignTypeSyncCommitteeSelectionProof       SignType = "SYNC_COMMITTEE_SELECTION_PROOF"
+	SignTypeSyncCommitteeContributionAndProof SignType = "SYNC_COMMITTEE_CONTRIBUTION_AND_PROOF"
+	SignTypeValidatorRegistration             SignType = "VALIDATOR_REGISTRATION"
+)
+
+// Fork names sent as version of blocks and aggregates.
+const (
+	ForkPhase0    = "PHASE0"
+	ForkAltair    = "ALTAIR"
+	ForkBellatrix = "BELLATRIX"
+	ForkCapella   = "CAPELLA"
+	ForkDeneb     = "DENEB"
+	ForkElectra   = "ELECTRA"
+	ForkFulu      = "FULU"
+)
+
+var (
+	ErrUnsupportedSignType  = errors.New("unsupported signing request type")
+	ErrMissingSignData      = errors.New("signing request is missing the data of its type")
+	ErrInvalidSignData      = errors.New("invalid data of signing request")
+	ErrMissingForkInfo      = errors.New("signing request is missing fork_info")
+	ErrMissingBlockHeader   = errors.New("only blocks sent as block_header are supported")
+	ErrMissingCommitteeBits = errors.New("attestation is missing committee_bits")
+	ErrUnknownFork          = errors.New("unknown fork version")
+	ErrSigningRootMismatch  = errors.New("signingRoot does not match the signing root of the message")
+
+	ErrGenesisValidatorsRootMismatch = errors.New("genesis validators root does not match the configured network")
+)
+
+// electraForks are the forks with the attestation layout introduced in Electra.
+var electraForks = map[string]bool{ForkElectra: true, ForkFulu: true}
+
+var knownForks = map[string]bool{
+	ForkPhase0:    true,
+	ForkAltair:    true,
+	ForkBellatrix: true,
+	ForkCapella:   true,
+	ForkDeneb:     true,
+	ForkElectra:   true,
+	ForkFulu:      true,
+}
+
+type Fork struct {
+	PreviousVersion Version `json:"previous_version"`
+	CurrentVersion  Version `json:"current_version"`
+	Epoch           Uint64  `json:"epoch"`
+}
+
+// ForkInfo is the fork of the beacon state the message is signed for.
+type ForkInfo struct {
+	Fork                  Fork `json:"fork"`
+	GenesisValidatorsRoot Root `json:"genesis_validators_root"`
+}
+
+// BeaconBlockRequest is a block to sign. Validator clients send the header of the block since Bellatrix.
+type BeaconBlockRequest struct {
+	Version     string             `json:"version"`
+	BlockHeader *BeaconBlockHeader `json:"block_header"`
+	Block       json.RawMessage    `json:"block"`
+}
+
+// AggregateAndProofV2Request is an aggregate and proof together with the fork it was created in.
+type AggregateAndProofV2Request struct {
+	Version string            `json:"version"`
+	Data    AggregateAndProof `json:"data"`
+}
+
+type AggregationSlot struct {
+	Slot Uint64 `json:"slot"`
+}
+
+type RandaoReveal struct {
+	Epoch Uint64 `json:"epoch"`
+}
+
+type SyncCommitteeMessage struct {
+	BeaconBlockRoot Root   `json:"beacon_block_root"`
+	Slot            Uint64 `json:"slot"`
+}
+
+// SigningRequest is the body of POST /api/v1/eth2/sign/{identifier} of the Web3Signer eth2 API.
+type SigningRequest struct {
+	Type                        SignType                     `json:"type"`
+	ForkInfo                    *ForkInfo                    `json:"fork_info"`
+	SigningRoot                 *Root                        `json:"signingRoot"`
+	BeaconBlock                 *BeaconBlockRequest          `json:"beacon_block"`
+	Attestation                 *AttestationData             `json:"attestation"`
+	AggregationSlot             *AggregationSlot             `json:"aggregation_slot"`
+	AggregateAndProof           json.RawMessage              `json:"aggregate_and_proof"`
+	RandaoReveal                *RandaoReveal                `json:"randao_reveal"`
+	VoluntaryExit               *VoluntaryExit               `json:"voluntary_exit"`
+	SyncCommitteeMessage        *SyncCommitteeMessage        `json:"sync_committee_message"`
+	SyncAggregatorSelectionData *SyncAggregatorSelectionData `json:"sync_aggregator_selection_data"`
+	ContributionAndProof        *ContributionAndProof        `json:"contribution_and_proof"`
+	ValidatorRegistration       *ValidatorRegistration       `json:"validator_registration"`
+}
+
+// ComputeSigningRoot returns the signing root of the message of the request on the network.
+// A signingRoot sent with the request must match it, the service never signs a root it did not compute.
+func (r *SigningRequest) ComputeSigningRoot(network *Network) (Root, error) {
+	signingRoot, err := r.computeSigningRoot(network)
+	if err != nil {
+		return Root{}, err
+	}
+
+	if r.SigningRoot != nil && *r.SigningRoot != signingRoot {
+		return Root{}, ErrSigningRootMismatch
+	}
+
+	return signingRoot, nil
+}
+
+func (r *SigningRequest) computeSigningRoot(network *Network) (Root, error) {
+	// builder registrations are signed independently of the fork of the beacon chain
+	if r.Type == SignTypeValidatorRegistration {
+		if r.ValidatorRegistration == nil {
+			return Root{}, ErrMissingSignData
+		}
+
+		domain := ComputeDomain(DomainApplicationBuilder, network.GenesisForkVersion, Root{})
+		return ComputeSigningRoot(r.ValidatorRegistration.HashTreeRoot(), domain), nil
+	}
+
+	if r.ForkInfo == nil {
+		return Root{}, ErrMissingForkInfo
+	}
+	if r.ForkInfo.GenesisValidatorsRoot != network.GenesisValidatorsRoot {
+		return Root{}, ErrGenesisValidatorsRootMismatch
+	}
+
+	switch r.Type {
+	case SignTypeBlockV2:
+		if r.BeaconBlock == nil {
+			return Root{}, ErrMissingSignData
+		}
+		if !knownForks[r.BeaconBlock.Version] {
+			return Root{}, ErrUnknownFork
+		}
+		if r.BeaconBlock.BlockHeader == nil {
+			return Root{}, ErrMissingBlockHeader
+		}
+
+		header := r.BeaconBlock.BlockHeader
+		return r.signingRoot(header.HashTreeRoot(), DomainBeaconProposer, ComputeEpochAtSlot(header.Slot)), nil
+
+	case SignTypeAttestation:
+		if r.Attestation == nil {
+			return Root{}, ErrMissingSignData
+		}
+
+		return r.signingRoot(r.Attestation.HashTreeRoot(), DomainBeaconAttester, r.Attestation.Target.Epoch), nil
+
+	case SignTypeAggregationSlot:
+		if r.AggregationSlot == nil {
+			return Root{}, ErrMissingSignData
+		}
+
+		slot := r.AggregationSlot.Slot
+		return r.signingRoot(uint64Root(uint64(slot)), DomainSelectionProof, ComputeEpochAtSlot(slot)), nil
+
+	case SignTypeAggregateAndProof, SignTypeAggregateAndProofV2:
+		aggregateAndProof, electra, err := r.aggregateAndProof()
+		if err != nil {
+			return Root{}, err
+		}
+
+		root, err := aggregateAndProof.HashTreeRoot(electra)
+		if err != nil {
+			return Root{}, err
+		}
+
+		epoch := ComputeEpochAtSlot(aggregateAndProof.Aggregate.Data.Slot)
+		return r.signingRoot(root, DomainAggregateAndProof, epoch), nil
+
+	case SignTypeRandaoReveal:
+		if r.RandaoReveal == nil {
+			return Root{}, ErrMissingSignData
+		}
+
+		epoch := r.RandaoReveal.Epoch
+		return r.signingRoot(uint64Root(uint64(epoch)), DomainRandao, epoch), nil
+
+	case SignTypeVoluntaryExit:
+		if r.VoluntaryExit == nil {
+			return Root{}, ErrMissingSignData
+		}
+
+		return VoluntaryExitSigningRoot(r.VoluntaryExit, network), nil
+
+	case SignTypeSyncCommitteeMessage:
+		if r.SyncCommitteeMessage == nil {
+			return Root{}, ErrMissingSignData
+		}
+
+		message := r.SyncCommitteeMessage
+		return r.signingRoot(message.BeaconBlockRoot, DomainSyncCommittee, ComputeEpochAtSlot(message.Slot)), nil
+
+	case SignTypeSyncCommitteeSelectionProof:
+		if r.SyncAggregatorSelectionData == nil {
+			return Root{}, ErrMissingSignData
+		}
+
+		data := r.SyncAggregatorSelectionData
+		return r.signingRoot(data.HashTreeRoot(), DomainSyncCommitteeSelectionProof, ComputeEpochAtSlot(data.Slot)), nil
+
+	case SignTypeSyncCommitteeContributionAndProof:
+		if r.ContributionAndProof == nil {
+			return Root{}, ErrMissingSignData
+		}
+
+		contribution := r.ContributionAndProof
+		epoch := ComputeEpochAtSlot(contribution.Contribution.Slot)
+		return r.signingRoot(contribution.HashTreeRoot(), DomainContributionAndProof, epoch), nil
+	}
+
+	return Root{}, ErrUnsupportedSignType
+}
+
+// aggregateAndProof decodes the aggregate and proof of the request and tells whether it uses the Electra layout.
+func (r *SigningRequest) aggregateAndProof() (*AggregateAndProof, bool, error) {
+	if len(r.AggregateAndProof) == 0 {
+		return nil, false, ErrMissingSignData
+	}
+
+	if r.Type == SignTypeAggregateAndProof {
+		var aggregateAndProof AggregateAndProof
+		if err := json.Unmarshal(r.AggregateAndProof, &aggregateAndProof); err != nil {
+			return nil, false, fmt.Errorf("%w: %v", ErrInvalidSignData, err)
+		}
+
+		return &aggregateAndProof, false, nil
+	}
+
+	var request AggregateAndProofV2Request
+	if err := json.Unmarshal(r.AggregateAndProof, &request); err != nil {
+		return nil, false, fmt.Errorf("%w: %v", ErrInvalidSignData, err)
+	}
+	if !knownForks[request.Version] {
+		return nil, false, ErrUnknownFork
+	}
+
+	return &request.Data, electraForks[request.Version], nil
+}
+
+// signingRoot returns the signing root of an object signed with the fork version active at epoch.
+func (r *SigningRequest) signingRoot(objectRoot Root, domainType DomainType, epoch Uint64) Root {
+	forkVersion := r.ForkInfo.Fork.CurrentVersion
+	if epoch < r.ForkInfo.Fork.Epoch {
+		forkVersion = r.ForkInfo.Fork.PreviousVersion
+	}
+
+	domain := ComputeDomain(domainType, forkVersion, r.ForkInfo.GenesisValidatorsRoot)
+	return ComputeSigningRoot(objectRoot, domain)
+}
@@ -0,0 +1,203 @@
+package eth2
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testForkInfo = `"fork_info": {
+	"fork": {"previous_version": "0x04000000", "current_version": "0x05000000", "epoch": "10"},
+	"genesis_validators_root": "0x4b363db94e286120d76eb905340fdd4e54bfe9f06bf33ff6cf5ad27f511bfe95"
+}`
+
+var (
+	testPreviousVersion       = Version{0x04, 0x00, 0x00, 0x00}
+	testCurrentVersion        = Version{0x05, 0x00, 0x00, 0x00}
+	testGenesisValidatorsRoot = mustDecodeRoot("4b363db94e286120d76eb905340fdd4e54bfe9f06bf33ff6cf5ad27f511bfe95")
+)
+
+func testRoot(i byte) Root {
+	var root Root
+	root[31] = i
+	return root
+}
+
+func TestComputeSigningRoot(t *testing.T) {
+	network, err := GetNetwork(NetworkMainnet)
+	assert.NoError(t, err)
+
+	header := BeaconBlockHeader{Slot: 352, ProposerIndex: 5, ParentRoot: testRoot(1), StateRoot: testRoot(2), BodyRoot: testRoot(3)}
+	attestation := AttestationData{Slot: 300, Index: 1, BeaconBlockRoot: testRoot(1), Source: Checkpoint{Epoch: 8, Root: testRoot(2)}, Target: Checkpoint{Epoch: 9, Root: testRoot(3)}}
+	selection := SyncAggregatorSelectionData{Slot: 320, SubcommitteeIndex: 2}
+	exit := VoluntaryExit{Epoch: 11, ValidatorIndex: 42}
+
+	for _, test := range []struct {
+		name        string
+		body        string
+		objectRoot  Root
+		domainType  DomainType
+		forkVersion Version
+	}{
+		{
+			name:        "block header after fork",
+			body:        `"type": "BLOCK_V2", "beacon_block": {"version": "DENEB", "block_header": {"slot": "352", "proposer_index": "5", "parent_root": "0x` + fmt.Sprintf("%064x", 1) + `", "state_root": "0x` + fmt.Sprintf("%064x", 2) + `", "body_root": "0x` + fmt.Sprintf("%064x", 3) + `"}}`,
+			objectRoot:  header.HashTreeRoot(),
+			domainType:  DomainBeaconProposer,
+			forkVersion: testCurrentVersion,
+		},
+		{
+			name:        "attestation before fork uses the target epoch",
+			body:        `"type": "ATTESTATION", "attestation": {"slot": "300", "index": "1", "beacon_block_root": "0x` + fmt.Sprintf("%064x", 1) + `", "source": {"epoch": "8", "root": "0x` + fmt.Sprintf("%064x", 2) + `"}, "target": {"epoch": "9", "root": "0x` + fmt.Sprintf("%064x", 3) + `"}}`,
+			objectRoot:  attestation.HashTreeRoot(),
+			domainType:  DomainBeaconAttester,
+			forkVersion: testPreviousVersion,
+		},
+		{
+			name:        "aggregation slot",
+			body:        `"type": "AGGREGATION_SLOT", "aggregation_slot": {"slot": "320"}`,
+			objectRoot:  uint64Root(320),
+			domainType:  DomainSelectionProof,
+			forkVersion: testCurrentVersion,
+		},
+		{
+			name:        "randao reveal",
+			body:        `"type": "RANDAO_REVEAL", "randao_reveal": {"epoch": "9"}`,
+			objectRoot:  uint64Root(9),
+			domainType:  DomainRandao,
+			forkVersion: testPreviousVersion,
+		},
+		{
+			name:        "sync committee message signs the block root",
+			body:        `"type": "SYNC_COMMITTEE_MESSAGE", "sync_committee_message": {"beacon_block_root": "0x` + fmt.Sprintf("%064x", 7) + `", "slot": "320"}`,
+			objectRoot:  testRoot(7),
+			domainType:  DomainSyncCommittee,
+			forkVersion: testCurrentVersion,
+		},
+		{
+			name:        "sync committee selection proof",
+			body:        `"type": "SYNC_COMMITTEE_SELECTION_PROOF", "sync_aggregator_selection_data": {"slot": "320", "subcommittee_index": "2"}`,
+			objectRoot:  selection.HashTreeRoot(),
+			domainType:  DomainSyncCommitteeSelectionProof,
+			forkVersion: testCurrentVersion,
+		},
+		{
+			name:        "voluntary exit is signed with the capella fork version",
+			body:        `"type": "VOLUNTARY_EXIT", "voluntary_exit": {"epoch": "11", "validator_index": "42"}`,
+			objectRoot:  exit.HashTreeRoot(),
+			domainType:  DomainVoluntaryExit,
+			forkVersion: network.CapellaForkVersion,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			var request SigningRequest
+			assert.NoError(t, json.Unmarshal([]byte(`{`+test.body+`, `+testForkInfo+`}`), &request))
+
+			signingRoot, err := request.ComputeSigningRoot(network)
+			assert.NoError(t, err)
+
+			domain := ComputeDomain(test.domainType, test.forkVersion, testGenesisValidatorsRoot)
+			assert.Equal(t, ComputeSigningRoot(test.objectRoot, domain), signingRoot)
+		})
+	}
+}
+
+func TestComputeSigningRootValidatorRegistration(t *testing.T) {
+	network, err := GetNetwork(NetworkHoodi)
+	assert.NoError(t, err)
+
+	// registrations do not depend on the fork and are sent without fork_info
+	var request SigningRequest
+	body := `{"type": "VALIDATOR_REGISTRATION", "validator_registration": {
+		"fee_recipient": "0x1234567890123456789012345678901234567890",
+		"gas_limit": "30000000",
+		"timestamp": "1700000000",
+		"pubkey": "0x` + fmt.Sprintf("%096x", 1) + `"
+	}}`
+	assert.NoError(t, json.Unmarshal([]byte(body), &request))
+
+	signingRoot, err := request.ComputeSigningRoot(network)
+	assert.NoError(t, err)
+
+	domain := ComputeDomain(DomainApplicationBuilder, network.GenesisForkVersion, Root{})
+	assert.Equal(t, ComputeSigningRoot(request.ValidatorRegistration.HashTreeRoot(), domain), signingRoot)
+}
+
+func TestComputeSigningRootAggregateAndProof(t *testing.T) {
+	network, err := GetNetwork(NetworkMainnet)
+	assert.NoError(t, err)
+
+	aggregate := `{"aggregator_index": "7", "aggregate": {"aggregation_bits": "0x0b06", "data": {"slot": "33", "index": "2",
+		"beacon_block_root": "0x` + fmt.Sprintf("%064x", 1) + `",
+		"source": {"epoch": "0", "root": "0x` + fmt.Sprintf("%064x", 2) + `"},
+		"target": {"epoch": "1", "root": "0x` + fmt.Sprintf("%064x", 3) + `"}},
+		"signature": "0x` + fmt.Sprintf("%0192x", 4) + `", "committee_bits": "0x0100000000000000"},
+		"selection_proof": "0x` + fmt.Sprintf("%0192x", 5) + `"}`
+
+	// expected roots were computed with an independent SSZ implementation
+	for _, test := range []struct {
+		body       string
+		objectRoot string
+	}{
+		{`"type": "AGGREGATE_AND_PROOF", "aggregate_and_proof": ` + aggregate, "9c85aca45f1b9e7e040804a36479e499943762076d83a7ba1b697586a127f5f1"},
+		{`"type": "AGGREGATE_AND_PROOF_V2", "aggregate_and_proof": {"version": "DENEB", "data": ` + aggregate + `}`, "9c85aca45f1b9e7e040804a36479e499943762076d83a7ba1b697586a127f5f1"},
+		{`"type": "AGGREGATE_AND_PROOF_V2", "aggregate_and_proof": {"version": "ELECTRA", "data": ` + aggregate + `}`, "545857e67c3bdb2a3e5c97d6990d1a87fd2ad485c82f4a8e6e7945536f3dab6e"},
+	} {
+		var request SigningRequest
+		assert.NoError(t, json.Unmarshal([]byte(`{`+test.body+`, `+testForkInfo+`}`), &request))
+
+		signingRoot, err := request.ComputeSigningRoot(network)
+		assert.NoError(t, err)
+
+		var objectRoot Root
+		assert.NoError(t, objectRoot.UnmarshalText([]byte("0x"+test.objectRoot)))
+		domain := ComputeDomain(DomainAggregateAndProof, testPreviousVersion, testGenesisValidatorsRoot)
+		assert.Equal(t, ComputeSigningRoot(objectRoot, domain), signingRoot)
+	}
+}
+
+func TestComputeSigningRootErrors(t *testing.T) {
+	network, err := GetNetwork(NetworkMainnet)
+	assert.NoError(t, err)
+
+	for _, test := range []struct {
+		name string
+		body string
+		err  error
+	}{
+		{"unsupported type", `{"type": "DEPOSIT", ` + testForkInfo + `}`, ErrUnsupportedSignType},
+		{"missing data", `{"type": "ATTESTATION", ` + testForkInfo + `}`, ErrMissingSignData},
+		{"missing fork info", `{"type": "RANDAO_REVEAL", "randao_reveal": {"epoch": "1"}}`, ErrMissingForkInfo},
+		{"other chain", `{"type": "RANDAO_REVEAL", "randao_reveal": {"epoch": "1"}, "fork_info": {"fork": {}, "genesis_validators_root": "0x` + fmt.Sprintf("%064x", 1) + `"}}`, ErrGenesisValidatorsRootMismatch},
+		{"full block", `{"type": "BLOCK_V2", "beacon_block": {"version": "PHASE0", "block": {"slot": "1"}}, ` + testForkInfo + `}`, ErrMissingBlockHeader},
+		{"unknown fork", `{"type": "BLOCK_V2", "beacon_block": {"version": "UNKNOWN"}, ` + testForkInfo + `}`, ErrUnknownFork},
+		{"electra aggregate without committee bits", `{"type": "AGGREGATE_AND_PROOF_V2", "aggregate_and_proof": {"version": "ELECTRA", "data": {"aggregate": {"aggregation_bits": "0x01"}}}, ` + testForkInfo + `}`, ErrMissingCommitteeBits},
+		{"empty bitlist", `{"type": "AGGREGATE_AND_PROOF", "aggregate_and_proof": {"aggregate": {"aggregation_bits": "0x00"}}, ` + testForkInfo + `}`, ErrInvalidBitlist},
+		{"signing root mismatch", `{"type": "RANDAO_REVEAL", "randao_reveal": {"epoch": "1"}, "signingRoot": "0x` + fmt.Sprintf("%064x", 1) + `", ` + testForkInfo + `}`, ErrSigningRootMismatch},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			var request SigningRequest
+			assert.NoError(t, json.Unmarshal([]byte(test.body), &request))
+
+			_, err := request.ComputeSigningRoot(network)
+			assert.ErrorIs(t, err, test.err)
+		})
+	}
+}
+
+func TestComputeSigningRootMatchingSigningRoot(t *testing.T) {
+	network, err := GetNetwork(NetworkMainnet)
+	assert.NoError(t, err)
+
+	var request SigningRequest
+	assert.NoError(t, json.Unmarshal([]byte(`{"type": "RANDAO_REVEAL", "randao_reveal": {"epoch": "1"}, `+testForkInfo+`}`), &request))
+	signingRoot, err := request.ComputeSigningRoot(network)
+	assert.NoError(t, err)
+
+	request.SigningRoot = &signingRoot
+	result, err := request.ComputeSigningRoot(network)
+	assert.NoError(t, err)
+	assert.Equal(t, signingRoot, result)
+}
@@ -0,0 +1,44 @@
+package eth2
+
+import "validator-service/internal/keys"
+
+// BLSToExecutionChange changes 0x00 withdrawal credentials of a validator to an execution address.
+type BLSToExecutionChange struct {
+	ValidatorIndex     Uint64           `json:"validator_index"`
+	FromBLSPubkey      BLSPubkey        `json:"from_bls_pubkey"`
+	ToExecutionAddress ExecutionAddress `json:"to_execution_address"`
+}
+
+func (c *BLSToExecutionChange) HashTreeRoot() Root {
+	return containerRoot(
+		uint64Root(uint64(c.ValidatorIndex)),
+		bytesRoot(c.FromBLSPubkey[:]),
+		bytesRoot(c.ToExecutionAddress[:]),
+	)
+}
+
+// SignedBLSToExecutionChange is a BLS to execution change in the format of the beacon node API, ready
+// to be submitted to /eth/v1/beacon/pool/bls_to_execution_changes.
+type SignedBLSToExecutionChange struct {
+	Message   BLSToExecutionChange `json:"message"`
+	Signature BLSSignature         `json:"signature"`
+}
+
+// NewSignedBLSToExecutionChange signs a change of the withdrawal credentials of the validator with the given
+// index to address with its BLS withdrawal key. Changes are signed with the genesis fork version, so they are
+// valid in every fork since Capella.
+func NewSignedBLSToExecutionChange(withdrawalKeypair *keys.Keypair, validatorIndex uint64, address ExecutionAddress, network *Network) *SignedBLSToExecutionChange {
+	change := BLSToExecutionChange{
+		ValidatorIndex:     Uint64(validatorIndex),
+		FromBLSPubkey:      withdrawalKeypair.Public.Serialize(),
+		ToExecutionAddress: address,
+	}
+
+	domain := ComputeDomain(DomainBLSToExecutionChange, network.GenesisForkVersion, network.GenesisValidatorsRoot)
+	signingRoot := ComputeSigningRoot(change.HashTreeRoot(), domain)
+
+	return &SignedBLSToExecutionChange{
+		Message:   change,
+		Signature: withdrawalKeypair.Sign(signingRoot[:]),
+	}
+}
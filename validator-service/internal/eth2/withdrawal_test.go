@@ -0,0 +1,31 @@
+package eth2
+
+import (
+	"testing"
+
+	blsu "github.com/protolambda/bls12-381-util"
+	"github.com/stretchr/testify/assert"
+	"validator-service/internal/keys"
+)
+
+func TestNewSignedBLSToExecutionChange(t *testing.T) {
+	network, err := GetNetwork(NetworkMainnet)
+	assert.NoError(t, err)
+
+	withdrawalKeypair, err := keys.GenerateKeypair()
+	assert.NoError(t, err)
+
+	address := ExecutionAddress{0x12, 0x34}
+	change := NewSignedBLSToExecutionChange(withdrawalKeypair, 42, address, network)
+	assert.Equal(t, Uint64(42), change.Message.ValidatorIndex)
+	assert.Equal(t, BLSPubkey(withdrawalKeypair.Public.Serialize()), change.Message.FromBLSPubkey)
+	assert.Equal(t, address, change.Message.ToExecutionAddress)
+
+	// mainnet changes are signed with the genesis fork version 0x00000000, not the current fork
+	domain := ComputeDomain(DomainType{0x0a, 0x00, 0x00, 0x00}, Version{}, network.GenesisValidatorsRoot)
+	signingRoot := ComputeSigningRoot(change.Message.HashTreeRoot(), domain)
+
+	var signature blsu.Signature
+	assert.NoError(t, signature.Deserialize((*[96]byte)(&change.Signature)))
+	assert.True(t, blsu.Verify(withdrawalKeypair.Public, signingRoot[:], &signature))
+}
@@ -0,0 +1,103 @@
+package events
+
+import (
+	"sync"
+
+	"validator-service/internal/models"
+)
+
+// SubscriptionBuffer is the number of events a subscriber may fall behind before events are dropped.
+const SubscriptionBuffer = 256
+
+type Type string
+
+const (
+	// StatusChanged is published when the status or the number of generated keys of a request changed.
+	StatusChanged Type = "status"
+	// KeyGenerated is published for every key stored for a request.
+	KeyGenerated Type = "key"
+)
+
+// Event is a change of a validator request. Status events carry the status and progress of the
+// request, key events the public key and derivation index of the stored key.
+type Event struct {
+	Type               Type
+	ValidatorRequestID uint
+	Status             models.RequestStatus
+	KeysGenerated      uint
+	KeysTotal          uint
+	Pubkey             string
+	DerivationIndex    uint
+}
+
+// Bus delivers the events of validator requests to the subscribers of this process. Publishing never
+// blocks: events are dropped for subscribers that fell behind, so subscribers must be able to catch up
+// from the database, which also covers requests processed by other replicas.
+type Bus struct {
+	mu          sync.Mutex
+	subscribers map[uint]map[*Subscription]struct{}
+}
+
+// Subscription receives the events of one validator request until it is closed.
+type Subscription struct {
+	C <-chan Event
+
+	bus                *Bus
+	validatorRequestID uint
+	events             chan Event
+	once               sync.Once
+}
+
+func NewBus() *Bus {
+	return &Bus{subscribers: map[uint]map[*Subscription]struct{}{}}
+}
+
+// Subscribe returns a subscription to the events of the validator request.
+func (b *Bus) Subscribe(validatorRequestID uint) *Subscription {
+	events := make(chan Event, SubscriptionBuffer)
+	subscription := &Subscription{
+		C:                  events,
+		bus:                b,
+		validatorRequestID: validatorRequestID,
+		events:             events,
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.subscribers[validatorRequestID] == nil {
+		b.subscribers[validatorRequestID] = map[*Subscription]struct{}{}
+	}
+	b.subscribers[validatorRequestID][subscription] = struct{}{}
+
+	return subscription
+}
+
+// Publish delivers the event to the subscribers of its validator request.
+func (b *Bus) Publish(event Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for subscription := range b.subscribers[event.ValidatorRequestID] {
+		select {
+		case subscription.events <- event:
+		default:
+		}
+	}
+}
+
+// Close unsubscribes and closes C.
+func (s *Subscription) Close() {
+	s.once.Do(func() {
+		s.bus.mu.Lock()
+		defer s.bus.mu.Unlock()
+
+		subscriptions := s.bus.subscribers[s.validatorRequestID]
+		delete(subscriptions, s)
+		if len(subscriptions) == 0 {
+			delete(s.bus.subscribers, s.validatorRequestID)
+		}
+
+		close(s.events)
+	})
+}
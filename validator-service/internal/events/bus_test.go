@@ -0,0 +1,53 @@
+package events_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"validator-service/internal/events"
+	"validator-service/internal/models"
+)
+
+func TestPublishDeliversToSubscribersOfRequest(t *testing.T) {
+	bus := events.NewBus()
+	first := bus.Subscribe(1)
+	second := bus.Subscribe(1)
+	other := bus.Subscribe(2)
+	defer first.Close()
+	defer second.Close()
+	defer other.Close()
+
+	bus.Publish(events.Event{Type: events.StatusChanged, ValidatorRequestID: 1, Status: models.RequestProcessing})
+
+	for _, subscription := range []*events.Subscription{first, second} {
+		event := <-subscription.C
+		assert.Equal(t, events.StatusChanged, event.Type)
+		assert.Equal(t, models.RequestProcessing, event.Status)
+	}
+	assert.Empty(t, other.C)
+}
+
+func TestPublishDropsEventsOfSlowSubscribers(t *testing.T) {
+	bus := events.NewBus()
+	subscription := bus.Subscribe(1)
+	defer subscription.Close()
+
+	for i := 0; i < events.SubscriptionBuffer+10; i++ {
+		bus.Publish(events.Event{Type: events.KeyGenerated, ValidatorRequestID: 1, DerivationIndex: uint(i)})
+	}
+
+	assert.Len(t, subscription.C, events.SubscriptionBuffer)
+	assert.Equal(t, uint(0), (<-subscription.C).DerivationIndex)
+}
+
+func TestClose(t *testing.T) {
+	bus := events.NewBus()
+	subscription := bus.Subscribe(1)
+
+	subscription.Close()
+	subscription.Close()
+	bus.Publish(events.Event{Type: events.StatusChanged, ValidatorRequestID: 1})
+
+	_, ok := <-subscription.C
+	assert.False(t, ok)
+}
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"log"
+	"net/http"
+	"time"
+	"validator-service/internal/middlewares"
+	"validator-service/internal/models"
+	"validator-service/internal/repository"
+	"validator-service/internal/services"
+)
+
+const APIKeyRevoked = "API key revoked"
+
+const (
+	ErrInvalidRole       = "Invalid role, use viewer, operator or admin"
+	ErrAPIKeyNotFound    = "API key not found"
+	ErrLoadingAPIKeys    = "Failed to load API keys"
+	ErrCreatingAPIKey    = "Failed to create API key"
+	ErrRevokingAPIKey    = "Failed to revoke API key"
+	ErrRevokingOwnAPIKey = "The API key of the request cannot revoke itself"
+)
+
+type CreateAPIKeyRequest struct {
+	Role models.Role `json:"role"`
+}
+
+type APIKeyResponse struct {
+	Prefix    string      `json:"prefix"`
+	Role      models.Role `json:"role"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+type CreateAPIKeyResponse struct {
+	APIKeyResponse
+	Key string `json:"key"`
+}
+
+type RevokeAPIKeyResponse struct {
+	Prefix  string `json:"prefix"`
+	Message string `json:"message"`
+}
+
+func (h *Handler) ListAPIKeys(c *gin.Context) {
+	apiKeys, err := repository.GetAPIKeys(h.db, middlewares.TenantID(c))
+	if err != nil {
+		log.Printf("%s, error: %v", ErrLoadingAPIKeys, err)
+		c.JSON(http.StatusInternalServerError, &ErrorResponse{Error: ErrInternalServer})
+		return
+	}
+
+	response := []APIKeyResponse{}
+	for _, apiKey := range apiKeys {
+		response = append(response, toAPIKeyResponse(&apiKey))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// CreateAPIKey creates an API key of the tenant. The key is only part of this response, the service
+// only stores its hash.
+func (h *Handler) CreateAPIKey(c *gin.Context) {
+	var req CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Println(ErrInvalidRequestBody, err)
+		c.JSON(http.StatusBadRequest, &ErrorResponse{Error: ErrInvalidRequestBody})
+		return
+	}
+
+	apiKey, key, err := services.CreateAPIKey(h.db, middlewares.TenantID(c), req.Role)
+	if errors.Is(err, services.ErrInvalidRole) {
+		log.Println(ErrInvalidRole, req.Role)
+		c.JSON(http.StatusBadRequest, &ErrorResponse{Error: ErrInvalidRole})
+		return
+	}
+	if err != nil {
+		log.Printf("%s, error: %v", ErrCreatingAPIKey, err)
+		c.JSON(http.StatusInternalServerError, &ErrorResponse{Error: ErrInternalServer})
+		return
+	}
+
+	c.JSON(http.StatusOK, &CreateAPIKeyResponse{
+		APIKeyResponse: toAPIKeyResponse(apiKey),
+		Key:            key,
+	})
+}
+
+// RevokeAPIKey revokes an API key of the tenant. An API key cannot revoke itself, so that a tenant
+// always keeps an API key able to manage its keys.
+func (h *Handler) RevokeAPIKey(c *gin.Context) {
+	prefix := c.Param("prefix")
+
+	if prefix == middlewares.APIKeyPrefix(c) {
+		log.Printf("%s, api_key: %s", ErrRevokingOwnAPIKey, prefix)
+		c.JSON(http.StatusConflict, &ErrorResponse{Error: ErrRevokingOwnAPIKey})
+		return
+	}
+
+	revoked, err := repository.RevokeTenantAPIKey(h.db, middlewares.TenantID(c), prefix)
+	if err != nil {
+		log.Printf("%s, api_key: %s, error: %v", ErrRevokingAPIKey, prefix, err)
+		c.JSON(http.StatusInternalServerError, &ErrorResponse{Error: ErrInternalServer})
+		return
+	}
+	if !revoked {
+		log.Printf("%s, api_key: %s", ErrAPIKeyNotFound, prefix)
+		c.JSON(http.StatusNotFound, &ErrorResponse{Error: ErrAPIKeyNotFound})
+		return
+	}
+
+	c.JSON(http.StatusOK, &RevokeAPIKeyResponse{
+		Prefix:  prefix,
+		Message: APIKeyRevoked,
+	})
+}
+
+func toAPIKeyResponse(apiKey *models.APIKey) APIKeyResponse {
+	return APIKeyResponse{
+		Prefix:    apiKey.Prefix,
+		Role:      apiKey.Role,
+		CreatedAt: apiKey.CreatedAt,
+	}
+}
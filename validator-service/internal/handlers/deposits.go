@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"log"
+	"net/http"
+	"validator-service/internal/middlewares"
+	"validator-service/internal/models"
+	"validator-service/internal/repository"
+	"validator-service/internal/services"
+)
+
+const ErrBuildingDepositData = "Failed to build deposit data"
+
+func (h *Handler) GetDepositData(c *gin.Context) {
+	reqID := c.Param("request_id")
+
+	validatorRequest, err := repository.GetValidatorRequestByUUID(h.db, middlewares.TenantID(c), reqID)
+	if err != nil {
+		log.Printf("%s, request_id: %s", ErrRequestNotFound, reqID)
+		c.JSON(http.StatusNotFound, &ErrorResponse{Error: ErrRequestNotFound})
+		return
+	}
+
+	if validatorRequest.Status != models.RequestSuccessful {
+		log.Printf("%s, request_id: %s, status: %s", ErrRequestNotCompleted, reqID, validatorRequest.Status)
+		c.JSON(http.StatusConflict, &ErrorResponse{Error: ErrRequestNotCompleted})
+		return
+	}
+
+	depositData, err := services.BuildDepositData(validatorRequest, h.config.Network)
+	if err != nil {
+		log.Printf("%s, request_id: %s, error: %v", ErrBuildingDepositData, reqID, err)
+		c.JSON(http.StatusInternalServerError, &ErrorResponse{Error: ErrInternalServer})
+		return
+	}
+
+	c.JSON(http.StatusOK, depositData)
+}
@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"log"
+	"net/http"
+	"slices"
+	"time"
+	"validator-service/internal/events"
+	"validator-service/internal/middlewares"
+	"validator-service/internal/models"
+	"validator-service/internal/repository"
+)
+
+const (
+	// EventsPollInterval is how often event streams read the request from the database, to catch up
+	// with requests processed by other replicas and with events dropped by the event bus.
+	EventsPollInterval = 2 * time.Second
+	// EventsKeepAliveInterval is how often a comment is sent on idle event streams to keep proxies from closing them.
+	EventsKeepAliveInterval = 15 * time.Second
+)
+
+const ErrStreamingEvents = "Failed to stream request events"
+
+type RequestStatusEvent struct {
+	RequestId     string               `json:"request_id"`
+	Status        models.RequestStatus `json:"status"`
+	KeysGenerated uint                 `json:"keys_generated"`
+	KeysTotal     uint                 `json:"keys_total"`
+}
+
+type RequestKeyEvent struct {
+	RequestId       string `json:"request_id"`
+	Pubkey          string `json:"pubkey"`
+	DerivationIndex uint   `json:"derivation_index"`
+}
+
+// StreamRequestEvents streams the keys and status changes of a request as Server-Sent Events. The stream
+// starts with the keys stored so far and the current status, and ends once the request is completed,
+// failed or cancelled.
+func (h *Handler) StreamRequestEvents(c *gin.Context) {
+	reqID := c.Param("request_id")
+
+	validatorRequest, err := repository.GetValidatorRequestByUUIDWithoutKeys(h.db, middlewares.TenantID(c), reqID)
+	if err != nil {
+		log.Printf("%s, request_id: %s", ErrRequestNotFound, reqID)
+		c.JSON(http.StatusNotFound, &ErrorResponse{Error: ErrRequestNotFound})
+		return
+	}
+
+	// subscribe before the first read so that no change between the read and the subscription is missed
+	subscription := h.events.Subscribe(validatorRequest.ID)
+	defer subscription.Close()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	stream := &requestEventStream{c: c, h: h, validatorRequest: validatorRequest}
+	if !stream.catchUp() {
+		return
+	}
+
+	poll := time.NewTicker(EventsPollInterval)
+	defer poll.Stop()
+	keepAlive := time.NewTicker(EventsKeepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-h.streamsClosed:
+			return
+		case event := <-subscription.C:
+			if !stream.apply(event) {
+				return
+			}
+		case <-poll.C:
+			if !stream.catchUp() {
+				return
+			}
+		case <-keepAlive.C:
+			_, _ = c.Writer.WriteString(": keep-alive\n\n")
+			c.Writer.Flush()
+		}
+	}
+}
+
+// CloseStreams ends all event streams, so that they do not hold up a graceful shutdown.
+func (h *Handler) CloseStreams() {
+	h.closeStreams.Do(func() {
+		close(h.streamsClosed)
+	})
+}
+
+// requestEventStream sends the events of a request that were not sent yet. Keys are sent in the order
+// of their derivation index, and a status is only sent when it differs from the last one sent.
+type requestEventStream struct {
+	c                *gin.Context
+	h                *Handler
+	validatorRequest *models.ValidatorRequest
+	nextIndex        uint
+	lastStatus       *RequestStatusEvent
+}
+
+// apply sends a published event. It returns false once the stream is done.
+func (s *requestEventStream) apply(event events.Event) bool {
+	switch event.Type {
+	case events.KeyGenerated:
+		switch {
+		case event.DerivationIndex < s.nextIndex:
+			return true
+		case event.DerivationIndex > s.nextIndex:
+			// earlier keys were dropped by the event bus
+			return s.catchUp()
+		}
+		s.sendKey(event.Pubkey, event.DerivationIndex)
+		return true
+	case events.StatusChanged:
+		if event.KeysGenerated > s.nextIndex {
+			return s.catchUp()
+		}
+		return s.sendStatus(event.Status, event.KeysGenerated, event.KeysTotal)
+	}
+
+	return true
+}
+
+// catchUp sends the changes stored in the database. The status is read before the keys, so that
+// the keys stored together with a final status are always sent before the stream ends.
+func (s *requestEventStream) catchUp() bool {
+	validatorRequest, err := repository.GetValidatorRequestStatus(s.h.db, s.validatorRequest.ID)
+	if err != nil {
+		log.Printf("%s, request_id: %s, error: %v", ErrStreamingEvents, s.validatorRequest.RequestUUID, err)
+		return false
+	}
+
+	validatorKeys, err := repository.GetValidatorKeysFromIndex(s.h.db, validatorRequest.ID, s.nextIndex)
+	if err != nil {
+		log.Printf("%s, request_id: %s, error: %v", ErrStreamingEvents, s.validatorRequest.RequestUUID, err)
+		return false
+	}
+
+	for _, validatorKey := range validatorKeys {
+		s.sendKey(validatorKey.Key, validatorKey.DerivationIndex)
+	}
+
+	keysGenerated, err := repository.CountValidatorKeys(s.h.db, validatorRequest.ID)
+	if err != nil {
+		log.Printf("%s, request_id: %s, error: %v", ErrStreamingEvents, s.validatorRequest.RequestUUID, err)
+		return false
+	}
+
+	return s.sendStatus(validatorRequest.Status, uint(keysGenerated), validatorRequest.NumValidators)
+}
+
+func (s *requestEventStream) sendKey(pubkey string, derivationIndex uint) {
+	s.c.SSEvent(string(events.KeyGenerated), &RequestKeyEvent{
+		RequestId:       s.validatorRequest.RequestUUID,
+		Pubkey:          pubkey,
+		DerivationIndex: derivationIndex,
+	})
+	s.c.Writer.Flush()
+
+	s.nextIndex = derivationIndex + 1
+}
+
+// sendStatus sends the status unless it was sent last. It returns false when the request is no longer in progress.
+func (s *requestEventStream) sendStatus(status models.RequestStatus, keysGenerated uint, keysTotal uint) bool {
+	event := &RequestStatusEvent{
+		RequestId:     s.validatorRequest.RequestUUID,
+		Status:        status,
+		KeysGenerated: keysGenerated,
+		KeysTotal:     keysTotal,
+	}
+
+	if s.lastStatus == nil || *s.lastStatus != *event {
+		s.c.SSEvent(string(events.StatusChanged), event)
+		s.c.Writer.Flush()
+		s.lastStatus = event
+	}
+
+	return slices.Contains(models.RequestsInProgress, status)
+}
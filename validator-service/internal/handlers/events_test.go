@@ -0,0 +1,188 @@
+package handlers_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"validator-service/internal/events"
+	"validator-service/internal/handlers"
+	"validator-service/internal/models"
+	"validator-service/internal/repository"
+)
+
+type serverSentEvent struct {
+	Event string
+	Data  string
+}
+
+// streamEvents connects to the event stream of the request. The channel is closed when the stream ends.
+func streamEvents(t *testing.T, ctx context.Context, server *httptest.Server, requestUUID string) <-chan serverSentEvent {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/validators/"+requestUUID+"/events", nil)
+	assert.NoError(t, err)
+	req.Header.Set(tenantIDHeader, strconv.FormatUint(uint64(testTenantID), 10))
+
+	response, err := server.Client().Do(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "text/event-stream", response.Header.Get("Content-Type"))
+
+	stream := make(chan serverSentEvent)
+	go func() {
+		defer close(stream)
+		defer response.Body.Close()
+
+		var event serverSentEvent
+		scanner := bufio.NewScanner(response.Body)
+		for scanner.Scan() {
+			line := scanner.Text()
+			switch {
+			case strings.HasPrefix(line, "event:"):
+				event.Event = strings.TrimPrefix(line, "event:")
+			case strings.HasPrefix(line, "data:"):
+				event.Data = strings.TrimPrefix(line, "data:")
+			case line == "" && event.Event != "":
+				stream <- event
+				event = serverSentEvent{}
+			}
+		}
+	}()
+
+	return stream
+}
+
+func nextEvent(t *testing.T, stream <-chan serverSentEvent) serverSentEvent {
+	select {
+	case event, ok := <-stream:
+		assert.True(t, ok, "stream ended")
+		return event
+	case <-time.After(2 * handlers.EventsPollInterval):
+		t.Fatal("no event received")
+		return serverSentEvent{}
+	}
+}
+
+func assertKeyEvent(t *testing.T, event serverSentEvent, pubkey string, derivationIndex uint) {
+	assert.Equal(t, string(events.KeyGenerated), event.Event)
+
+	var keyEvent handlers.RequestKeyEvent
+	assert.NoError(t, json.Unmarshal([]byte(event.Data), &keyEvent))
+	assert.Equal(t, pubkey, keyEvent.Pubkey)
+	assert.Equal(t, derivationIndex, keyEvent.DerivationIndex)
+}
+
+func assertStatusEvent(t *testing.T, event serverSentEvent, status models.RequestStatus, keysGenerated uint, keysTotal uint) {
+	assert.Equal(t, string(events.StatusChanged), event.Event)
+
+	var statusEvent handlers.RequestStatusEvent
+	assert.NoError(t, json.Unmarshal([]byte(event.Data), &statusEvent))
+	assert.Equal(t, status, statusEvent.Status)
+	assert.Equal(t, keysGenerated, statusEvent.KeysGenerated)
+	assert.Equal(t, keysTotal, statusEvent.KeysTotal)
+}
+
+func assertStreamEnds(t *testing.T, stream <-chan serverSentEvent) {
+	select {
+	case event, ok := <-stream:
+		assert.False(t, ok, "unexpected event %v", event)
+	case <-time.After(2 * handlers.EventsPollInterval):
+		t.Fatal("stream did not end")
+	}
+}
+
+// newProcessingRequest stores a request of the test tenant with numValidators, of which the keys with
+// pubkeys were generated.
+func newProcessingRequest(t *testing.T, db *gorm.DB, numValidators uint, pubkeys ...string) *models.ValidatorRequest {
+	validatorRequest := &models.ValidatorRequest{
+		TenantID:      testTenantID,
+		RequestUUID:   "test_uuid",
+		NumValidators: numValidators,
+		Status:        models.RequestProcessing,
+	}
+	assert.NoError(t, repository.CreateValidatorRequest(db, validatorRequest))
+
+	for _, pubkey := range pubkeys {
+		storeKey(t, db, validatorRequest, pubkey)
+	}
+
+	return validatorRequest
+}
+
+// storeKey stores the next key of the request without publishing an event, like another replica does.
+func storeKey(t *testing.T, db *gorm.DB, validatorRequest *models.ValidatorRequest, pubkey string) {
+	count, err := repository.CountValidatorKeys(db, validatorRequest.ID)
+	assert.NoError(t, err)
+
+	assert.NoError(t, repository.CreateValidatorKey(db, &models.ValidatorKey{
+		ValidatorRequestID: validatorRequest.ID,
+		Key:                pubkey,
+		DerivationIndex:    uint(count),
+	}))
+}
+
+func TestStreamRequestEventsOfCompletedRequest(t *testing.T) {
+	db := setupTestDB(t)
+	server := httptest.NewServer(setupRouter(t, db))
+	defer server.Close()
+	validatorRequest := newSuccessfulRequest(t, db, oldFeeRecipient, "0x01", "0x02")
+
+	stream := streamEvents(t, context.Background(), server, validatorRequest.RequestUUID)
+	assertKeyEvent(t, nextEvent(t, stream), "0x01", 0)
+	assertKeyEvent(t, nextEvent(t, stream), "0x02", 1)
+	assertStatusEvent(t, nextEvent(t, stream), models.RequestSuccessful, 2, 2)
+	assertStreamEnds(t, stream)
+}
+
+func TestStreamRequestEventsEndsOnTerminalStatus(t *testing.T) {
+	db := setupTestDB(t)
+	server := httptest.NewServer(setupRouter(t, db))
+	defer server.Close()
+	validatorRequest := newProcessingRequest(t, db, 2, "0x01")
+
+	stream := streamEvents(t, context.Background(), server, validatorRequest.RequestUUID)
+	assertKeyEvent(t, nextEvent(t, stream), "0x01", 0)
+	assertStatusEvent(t, nextEvent(t, stream), models.RequestProcessing, 1, 2)
+
+	// the request is completed by another replica, the stream catches up by polling
+	storeKey(t, db, validatorRequest, "0x02")
+	_, err := repository.UpdateValidatorRequestStatus(db, validatorRequest, models.RequestsInProgress, models.RequestSuccessful)
+	assert.NoError(t, err)
+
+	assertKeyEvent(t, nextEvent(t, stream), "0x02", 1)
+	assertStatusEvent(t, nextEvent(t, stream), models.RequestSuccessful, 2, 2)
+	assertStreamEnds(t, stream)
+}
+
+func TestStreamRequestEventsCatchesUpAfterReconnect(t *testing.T) {
+	db := setupTestDB(t)
+	server := httptest.NewServer(setupRouter(t, db))
+	defer server.Close()
+	validatorRequest := newProcessingRequest(t, db, 3, "0x01")
+
+	ctx, disconnect := context.WithCancel(context.Background())
+	stream := streamEvents(t, ctx, server, validatorRequest.RequestUUID)
+	assertKeyEvent(t, nextEvent(t, stream), "0x01", 0)
+	assertStatusEvent(t, nextEvent(t, stream), models.RequestProcessing, 1, 3)
+	disconnect()
+
+	// keys generated while the client was disconnected are sent when it reconnects
+	storeKey(t, db, validatorRequest, "0x02")
+	storeKey(t, db, validatorRequest, "0x03")
+	_, err := repository.UpdateValidatorRequestStatus(db, validatorRequest, models.RequestsInProgress, models.RequestFailed)
+	assert.NoError(t, err)
+
+	stream = streamEvents(t, context.Background(), server, validatorRequest.RequestUUID)
+	assertKeyEvent(t, nextEvent(t, stream), "0x01", 0)
+	assertKeyEvent(t, nextEvent(t, stream), "0x02", 1)
+	assertKeyEvent(t, nextEvent(t, stream), "0x03", 2)
+	assertStatusEvent(t, nextEvent(t, stream), models.RequestFailed, 3, 3)
+	assertStreamEnds(t, stream)
+}
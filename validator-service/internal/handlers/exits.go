@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"log"
+	"net/http"
+	"validator-service/internal/eth2"
+	"validator-service/internal/middlewares"
+	"validator-service/internal/repository"
+	"validator-service/internal/services"
+)
+
+const (
+	ErrMissingValidatorIndex = "Missing validator index"
+	ErrSigningVoluntaryExit  = "Failed to sign voluntary exit"
+)
+
+// VoluntaryExitRequest selects the validator to exit. The beacon chain assigns the validator index
+// when the deposit is processed, it can be looked up by public key on a beacon node or explorer.
+type VoluntaryExitRequest struct {
+	ValidatorIndex *eth2.Uint64 `json:"validator_index"`
+	// Epoch is the earliest epoch the exit is valid in, the exit is valid in any epoch by default.
+	Epoch eth2.Uint64 `json:"epoch"`
+}
+
+func (h *Handler) CreateVoluntaryExit(c *gin.Context) {
+	pubkey, ok := parsePubkeyParam(c)
+	if !ok {
+		return
+	}
+
+	var req VoluntaryExitRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Println(ErrInvalidRequestBody, err)
+		c.JSON(http.StatusBadRequest, &ErrorResponse{Error: ErrInvalidRequestBody})
+		return
+	}
+
+	if req.ValidatorIndex == nil {
+		log.Printf("%s, pubkey: %s", ErrMissingValidatorIndex, pubkey)
+		c.JSON(http.StatusBadRequest, &ErrorResponse{Error: ErrMissingValidatorIndex})
+		return
+	}
+
+	validatorKey, err := repository.GetValidatorKeyByPubkey(h.db, middlewares.TenantID(c), pubkey)
+	if err != nil {
+		log.Printf("%s, pubkey: %s", ErrValidatorKeyNotFound, pubkey)
+		c.JSON(http.StatusNotFound, &ErrorResponse{Error: ErrValidatorKeyNotFound})
+		return
+	}
+
+	exit, err := services.SignVoluntaryExit(validatorKey, uint64(*req.ValidatorIndex), uint64(req.Epoch), h.config.Network)
+	if err != nil {
+		log.Printf("%s, pubkey: %s, error: %v", ErrSigningVoluntaryExit, pubkey, err)
+		c.JSON(http.StatusInternalServerError, &ErrorResponse{Error: ErrInternalServer})
+		return
+	}
+
+	c.JSON(http.StatusOK, exit)
+}
@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"log"
+	"net/http"
+	"time"
+	"validator-service/internal/middlewares"
+	"validator-service/internal/models"
+	"validator-service/internal/repository"
+	"validator-service/internal/services"
+	"validator-service/internal/utils"
+)
+
+const (
+	ErrUpdatingFeeRecipient      = "Failed to update fee recipient"
+	ErrLoadingFeeRecipients      = "Failed to load fee recipients"
+	ErrRequestInProgress         = "Request is in progress"
+	ErrLoadingFeeRecipientChange = "Failed to load fee recipient history"
+)
+
+type UpdateFeeRecipientRequest struct {
+	FeeRecipient string `json:"fee_recipient"`
+}
+
+type FeeRecipientsResponse struct {
+	RequestId           string            `json:"request_id"`
+	DefaultFeeRecipient string            `json:"default_fee_recipient"`
+	FeeRecipients       map[string]string `json:"fee_recipients"`
+}
+
+type KeyFeeRecipientResponse struct {
+	Pubkey       string `json:"pubkey"`
+	FeeRecipient string `json:"fee_recipient"`
+}
+
+type FeeRecipientChangeResponse struct {
+	Pubkey          string    `json:"pubkey,omitempty"`
+	OldFeeRecipient string    `json:"old_fee_recipient"`
+	NewFeeRecipient string    `json:"new_fee_recipient"`
+	ChangedAt       time.Time `json:"changed_at"`
+}
+
+func (h *Handler) GetFeeRecipients(c *gin.Context) {
+	reqID := c.Param("request_id")
+
+	validatorRequest, err := repository.GetValidatorRequestByUUIDWithPublicKeys(h.db, middlewares.TenantID(c), reqID)
+	if err != nil {
+		log.Printf("%s, request_id: %s", ErrRequestNotFound, reqID)
+		c.JSON(http.StatusNotFound, &ErrorResponse{Error: ErrRequestNotFound})
+		return
+	}
+
+	c.JSON(http.StatusOK, toFeeRecipientsResponse(validatorRequest, validatorRequest.Keys))
+}
+
+func (h *Handler) UpdateRequestFeeRecipient(c *gin.Context) {
+	reqID := c.Param("request_id")
+
+	feeRecipient, ok := bindFeeRecipient(c)
+	if !ok {
+		return
+	}
+
+	validatorRequest, err := repository.GetValidatorRequestByUUIDWithoutKeys(h.db, middlewares.TenantID(c), reqID)
+	if err != nil {
+		log.Printf("%s, request_id: %s", ErrRequestNotFound, reqID)
+		c.JSON(http.StatusNotFound, &ErrorResponse{Error: ErrRequestNotFound})
+		return
+	}
+
+	err = services.UpdateRequestFeeRecipient(h.db, validatorRequest, feeRecipient)
+	if errors.Is(err, services.ErrValidatorRequestInProgress) {
+		log.Printf("%s, request_id: %s", ErrRequestInProgress, reqID)
+		c.JSON(http.StatusConflict, &ErrorResponse{Error: ErrRequestInProgress})
+		return
+	}
+	if err != nil {
+		log.Printf("%s, request_id: %s, error: %v", ErrUpdatingFeeRecipient, reqID, err)
+		c.JSON(http.StatusInternalServerError, &ErrorResponse{Error: ErrInternalServer})
+		return
+	}
+
+	validatorKeys, err := repository.GetValidatorKeys(h.db, validatorRequest.ID)
+	if err != nil {
+		log.Printf("%s, request_id: %s, error: %v", ErrLoadingFeeRecipients, reqID, err)
+		c.JSON(http.StatusInternalServerError, &ErrorResponse{Error: ErrInternalServer})
+		return
+	}
+
+	c.JSON(http.StatusOK, toFeeRecipientsResponse(validatorRequest, validatorKeys))
+}
+
+func (h *Handler) UpdateKeyFeeRecipient(c *gin.Context) {
+	pubkey, ok := parsePubkeyParam(c)
+	if !ok {
+		return
+	}
+
+	feeRecipient, ok := bindFeeRecipient(c)
+	if !ok {
+		return
+	}
+
+	validatorKey, err := repository.GetValidatorKeyByPubkey(h.db, middlewares.TenantID(c), pubkey)
+	if err != nil {
+		log.Printf("%s, pubkey: %s", ErrValidatorKeyNotFound, pubkey)
+		c.JSON(http.StatusNotFound, &ErrorResponse{Error: ErrValidatorKeyNotFound})
+		return
+	}
+
+	if err := services.UpdateKeyFeeRecipient(h.db, validatorKey, feeRecipient); err != nil {
+		log.Printf("%s, pubkey: %s, error: %v", ErrUpdatingFeeRecipient, pubkey, err)
+		c.JSON(http.StatusInternalServerError, &ErrorResponse{Error: ErrInternalServer})
+		return
+	}
+
+	c.JSON(http.StatusOK, &KeyFeeRecipientResponse{
+		Pubkey:       validatorKey.Key,
+		FeeRecipient: validatorKey.FeeRecipient,
+	})
+}
+
+func (h *Handler) GetFeeRecipientHistory(c *gin.Context) {
+	reqID := c.Param("request_id")
+
+	validatorRequest, err := repository.GetValidatorRequestByUUIDWithPublicKeys(h.db, middlewares.TenantID(c), reqID)
+	if err != nil {
+		log.Printf("%s, request_id: %s", ErrRequestNotFound, reqID)
+		c.JSON(http.StatusNotFound, &ErrorResponse{Error: ErrRequestNotFound})
+		return
+	}
+
+	changes, err := repository.GetFeeRecipientChanges(h.db, validatorRequest.ID)
+	if err != nil {
+		log.Printf("%s, request_id: %s, error: %v", ErrLoadingFeeRecipientChange, reqID, err)
+		c.JSON(http.StatusInternalServerError, &ErrorResponse{Error: ErrInternalServer})
+		return
+	}
+
+	pubkeys := make(map[uint]string, len(validatorRequest.Keys))
+	for _, key := range validatorRequest.Keys {
+		pubkeys[key.ID] = key.Key
+	}
+
+	response := []FeeRecipientChangeResponse{}
+	for _, change := range changes {
+		var pubkey string
+		if change.ValidatorKeyID != nil {
+			pubkey = pubkeys[*change.ValidatorKeyID]
+		}
+
+		response = append(response, FeeRecipientChangeResponse{
+			Pubkey:          pubkey,
+			OldFeeRecipient: change.OldFeeRecipient,
+			NewFeeRecipient: change.NewFeeRecipient,
+			ChangedAt:       change.CreatedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func bindFeeRecipient(c *gin.Context) (string, bool) {
+	var req UpdateFeeRecipientRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Println(ErrInvalidRequestBody, err)
+		c.JSON(http.StatusBadRequest, &ErrorResponse{Error: ErrInvalidRequestBody})
+		return "", false
+	}
+
+	if !utils.ValidateAddress(req.FeeRecipient) {
+		log.Println(ErrInvalidFeeRecipient)
+		c.JSON(http.StatusBadRequest, &ErrorResponse{Error: ErrInvalidFeeRecipient})
+		return "", false
+	}
+
+	return req.FeeRecipient, true
+}
+
+func toFeeRecipientsResponse(validatorRequest *models.ValidatorRequest, validatorKeys []models.ValidatorKey) *FeeRecipientsResponse {
+	feeRecipients := make(map[string]string, len(validatorKeys))
+	for _, key := range validatorKeys {
+		feeRecipients[key.Key] = key.FeeRecipient
+	}
+
+	return &FeeRecipientsResponse{
+		RequestId:           validatorRequest.RequestUUID,
+		DefaultFeeRecipient: validatorRequest.FeeRecipient,
+		FeeRecipients:       feeRecipients,
+	}
+}
@@ -0,0 +1,43 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"validator-service/internal/handlers"
+)
+
+const (
+	oldFeeRecipient = "0x1111111111111111111111111111111111111111"
+	newFeeRecipient = "0x2222222222222222222222222222222222222222"
+)
+
+func TestGetFeeRecipientHistory(t *testing.T) {
+	db := setupTestDB(t)
+	r := setupRouter(t, db)
+	validatorRequest := newSuccessfulRequest(t, db, oldFeeRecipient, "0x01", "0x02")
+
+	w := serve(r, testTenantID, http.MethodPatch, "/validators/"+validatorRequest.RequestUUID+"/fee-recipient", `{"fee_recipient": "`+newFeeRecipient+`"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = serve(r, testTenantID, http.MethodGet, "/validators/"+validatorRequest.RequestUUID+"/fee-recipients/history", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var history []handlers.FeeRecipientChangeResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &history))
+	assert.Len(t, history, 3)
+
+	// the change of the request default has no pubkey, the changes of its keys name the key
+	var pubkeys []string
+	for _, change := range history {
+		pubkeys = append(pubkeys, change.Pubkey)
+		assert.Equal(t, oldFeeRecipient, change.OldFeeRecipient)
+		assert.Equal(t, newFeeRecipient, change.NewFeeRecipient)
+	}
+	assert.ElementsMatch(t, []string{"", "0x01", "0x02"}, pubkeys)
+
+	w = serve(r, otherTenantID, http.MethodGet, "/validators/"+validatorRequest.RequestUUID+"/fee-recipients/history", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"log"
+	"net/http"
+	"time"
+	"validator-service/internal/middlewares"
+	"validator-service/internal/models"
+	"validator-service/internal/repository"
+)
+
+const (
+	IdempotencyKeyHeader     = "Idempotency-Key"
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+	MaxIdempotencyKeyLength  = 255
+)
+
+const (
+	ErrInvalidIdempotencyKey  = "Invalid Idempotency-Key header"
+	ErrIdempotencyKeyConflict = "Idempotency-Key was already used with a different request"
+	ErrLoadingIdempotencyKey  = "Failed to load idempotency key"
+)
+
+// idempotencyRequestHash identifies the request body independent of its formatting.
+func idempotencyRequestHash(req interface{}) string {
+	body, _ := json.Marshal(req)
+	hash := sha256.Sum256(body)
+
+	return hex.EncodeToString(hash[:])
+}
+
+// replayIdempotentRequest answers a retry of an earlier request made with the same idempotency key.
+// It returns false when the key was not used yet.
+func (h *Handler) replayIdempotentRequest(c *gin.Context, key string, requestHash string) bool {
+	idempotencyKey, err := repository.GetIdempotencyKey(h.db, middlewares.TenantID(c), key, time.Now())
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false
+	}
+	if err != nil {
+		log.Printf("%s, idempotency_key: %s, error: %v", ErrLoadingIdempotencyKey, key, err)
+		c.JSON(http.StatusInternalServerError, &ErrorResponse{Error: ErrInternalServer})
+		return true
+	}
+
+	if idempotencyKey.RequestHash != requestHash {
+		log.Printf("%s, idempotency_key: %s", ErrIdempotencyKeyConflict, key)
+		c.JSON(http.StatusConflict, &ErrorResponse{Error: ErrIdempotencyKeyConflict})
+		return true
+	}
+
+	c.Header(IdempotentReplayedHeader, "true")
+	c.Data(idempotencyKey.ResponseStatus, "application/json; charset=utf-8", []byte(idempotencyKey.ResponseBody))
+	return true
+}
+
+// newIdempotencyKey records the response of the request that created validatorRequest.
+func (h *Handler) newIdempotencyKey(key string, requestHash string, validatorRequest *models.ValidatorRequest, status int, response interface{}) (*models.IdempotencyKey, error) {
+	body, err := json.Marshal(response)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.IdempotencyKey{
+		TenantID:           validatorRequest.TenantID,
+		Key:                key,
+		RequestHash:        requestHash,
+		ValidatorRequestID: validatorRequest.ID,
+		ResponseStatus:     status,
+		ResponseBody:       string(body),
+		ExpiresAt:          time.Now().UTC().Add(h.config.IdempotencyKeyTTL),
+	}, nil
+}
@@ -0,0 +1,86 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"validator-service/internal/handlers"
+	"validator-service/internal/models"
+)
+
+const createValidatorBody = `{"num_validators": 2, "fee_recipient": "0x1234567890123456789012345678901234567890"}`
+
+func createValidator(r *gin.Engine, tenantID uint, idempotencyKey string, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/validators", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(handlers.IdempotencyKeyHeader, idempotencyKey)
+	req.Header.Set(tenantIDHeader, strconv.FormatUint(uint64(tenantID), 10))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func requestID(t *testing.T, w *httptest.ResponseRecorder) string {
+	var response handlers.CreateValidatorResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.NotEmpty(t, response.RequestId)
+	return response.RequestId
+}
+
+func countValidatorRequests(t *testing.T, db *gorm.DB) int64 {
+	var count int64
+	assert.NoError(t, db.Model(&models.ValidatorRequest{}).Count(&count).Error)
+	return count
+}
+
+func TestCreateValidatorReplaysIdempotentRequest(t *testing.T) {
+	db := setupTestDB(t)
+	r := setupRouter(t, db)
+
+	first := createValidator(r, testTenantID, "key-1", createValidatorBody)
+	assert.Equal(t, http.StatusOK, first.Code)
+	assert.Empty(t, first.Header().Get(handlers.IdempotentReplayedHeader))
+
+	// the same body formatted differently is the same request
+	retry := createValidator(r, testTenantID, "key-1", strings.ReplaceAll(createValidatorBody, " ", ""))
+	assert.Equal(t, http.StatusOK, retry.Code)
+	assert.Equal(t, "true", retry.Header().Get(handlers.IdempotentReplayedHeader))
+	assert.Equal(t, requestID(t, first), requestID(t, retry))
+	assert.Equal(t, int64(1), countValidatorRequests(t, db))
+}
+
+func TestCreateValidatorRejectsReusedIdempotencyKey(t *testing.T) {
+	db := setupTestDB(t)
+	r := setupRouter(t, db)
+
+	first := createValidator(r, testTenantID, "key-1", createValidatorBody)
+	assert.Equal(t, http.StatusOK, first.Code)
+
+	conflict := createValidator(r, testTenantID, "key-1", strings.Replace(createValidatorBody, `"num_validators": 2`, `"num_validators": 3`, 1))
+	assert.Equal(t, http.StatusConflict, conflict.Code)
+	assert.Contains(t, conflict.Body.String(), handlers.ErrIdempotencyKeyConflict)
+	assert.Empty(t, conflict.Header().Get(handlers.IdempotentReplayedHeader))
+	assert.Equal(t, int64(1), countValidatorRequests(t, db))
+}
+
+func TestCreateValidatorScopesIdempotencyKeysToTenant(t *testing.T) {
+	db := setupTestDB(t)
+	r := setupRouter(t, db)
+
+	first := createValidator(r, testTenantID, "key-1", createValidatorBody)
+	assert.Equal(t, http.StatusOK, first.Code)
+
+	other := createValidator(r, otherTenantID, "key-1", createValidatorBody)
+	assert.Equal(t, http.StatusOK, other.Code)
+	assert.Empty(t, other.Header().Get(handlers.IdempotentReplayedHeader))
+	assert.NotEqual(t, requestID(t, first), requestID(t, other))
+	assert.Equal(t, int64(2), countValidatorRequests(t, db))
+}
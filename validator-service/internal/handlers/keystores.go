@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"log"
+	"net/http"
+	"time"
+	"validator-service/internal/keys"
+	"validator-service/internal/middlewares"
+	"validator-service/internal/models"
+	"validator-service/internal/repository"
+	"validator-service/internal/services"
+)
+
+const (
+	MinKeystorePasswordLength = 8
+	KeystoreFormatZip         = "zip"
+)
+
+const (
+	ErrInvalidKeystorePassword = "Keystore password must be at least 8 characters long"
+	ErrUnsupportedKDF          = "Unsupported key derivation function, use scrypt or pbkdf2"
+	ErrExportingKeystores      = "Failed to export keystores"
+)
+
+type ExportKeystoresRequest struct {
+	Password string `json:"password"`
+	KDF      string `json:"kdf"`
+}
+
+type ExportKeystoresResponse struct {
+	Keystores []*keys.Keystore `json:"keystores"`
+}
+
+func (h *Handler) ExportKeystores(c *gin.Context) {
+	reqID := c.Param("request_id")
+
+	var req ExportKeystoresRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Println(ErrInvalidRequestBody, err)
+		c.JSON(http.StatusBadRequest, &ErrorResponse{Error: ErrInvalidRequestBody})
+		return
+	}
+
+	if len([]rune(req.Password)) < MinKeystorePasswordLength {
+		log.Println(ErrInvalidKeystorePassword)
+		c.JSON(http.StatusBadRequest, &ErrorResponse{Error: ErrInvalidKeystorePassword})
+		return
+	}
+
+	if req.KDF == "" {
+		req.KDF = keys.KDFPBKDF2
+	}
+
+	if req.KDF != keys.KDFScrypt && req.KDF != keys.KDFPBKDF2 {
+		log.Println(ErrUnsupportedKDF, req.KDF)
+		c.JSON(http.StatusBadRequest, &ErrorResponse{Error: ErrUnsupportedKDF})
+		return
+	}
+
+	validatorRequest, err := repository.GetValidatorRequestByUUID(h.db, middlewares.TenantID(c), reqID)
+	if err != nil {
+		log.Printf("%s, request_id: %s", ErrRequestNotFound, reqID)
+		c.JSON(http.StatusNotFound, &ErrorResponse{Error: ErrRequestNotFound})
+		return
+	}
+
+	if validatorRequest.Status != models.RequestSuccessful {
+		log.Printf("%s, request_id: %s, status: %s", ErrRequestNotCompleted, reqID, validatorRequest.Status)
+		c.JSON(http.StatusConflict, &ErrorResponse{Error: ErrRequestNotCompleted})
+		return
+	}
+
+	keystores, err := services.ExportKeystores(validatorRequest, req.Password, req.KDF)
+	if err != nil {
+		log.Printf("%s, request_id: %s, error: %v", ErrExportingKeystores, reqID, err)
+		c.JSON(http.StatusInternalServerError, &ErrorResponse{Error: ErrInternalServer})
+		return
+	}
+
+	if c.Query("format") == KeystoreFormatZip {
+		h.writeKeystoresZip(c, validatorRequest.RequestUUID, keystores)
+		return
+	}
+
+	c.JSON(http.StatusOK, &ExportKeystoresResponse{Keystores: keystores})
+}
+
+func (h *Handler) writeKeystoresZip(c *gin.Context, requestUUID string, keystores []*keys.Keystore) {
+	timestamp := time.Now().Unix()
+
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"keystores-%s.zip\"", requestUUID))
+	c.Status(http.StatusOK)
+
+	archive := zip.NewWriter(c.Writer)
+	for _, keystore := range keystores {
+		file, err := archive.Create(keystore.FileName(timestamp))
+		if err != nil {
+			log.Printf("%s, request_id: %s, error: %v", ErrExportingKeystores, requestUUID, err)
+			return
+		}
+
+		if err := json.NewEncoder(file).Encode(keystore); err != nil {
+			log.Printf("%s, request_id: %s, error: %v", ErrExportingKeystores, requestUUID, err)
+			return
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		log.Printf("%s, request_id: %s, error: %v", ErrExportingKeystores, requestUUID, err)
+	}
+}
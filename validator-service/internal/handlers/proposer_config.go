@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"log"
+	"net/http"
+	"validator-service/internal/eth2"
+	"validator-service/internal/middlewares"
+	"validator-service/internal/repository"
+	"validator-service/internal/services"
+	"validator-service/internal/utils"
+)
+
+const (
+	ErrInvalidProposerConfigFormat = "Invalid format, expected teku, lodestar or prysm"
+	ErrInvalidDefaultFeeRecipient  = "Invalid default fee recipient address"
+	ErrLoadingProposerConfig       = "Failed to load proposer config"
+)
+
+type ProposerConfigQuery struct {
+	Format              string `form:"format"`
+	DefaultFeeRecipient string `form:"default_fee_recipient"`
+}
+
+func (h *Handler) GetRequestProposerConfig(c *gin.Context) {
+	reqID := c.Param("request_id")
+
+	query, fileName, ok := bindProposerConfigQuery(c)
+	if !ok {
+		return
+	}
+
+	validatorRequest, err := repository.GetValidatorRequestByUUIDWithPublicKeys(h.db, middlewares.TenantID(c), reqID)
+	if err != nil {
+		log.Printf("%s, request_id: %s", ErrRequestNotFound, reqID)
+		c.JSON(http.StatusNotFound, &ErrorResponse{Error: ErrRequestNotFound})
+		return
+	}
+
+	defaultFeeRecipient := validatorRequest.FeeRecipient
+	if query.DefaultFeeRecipient != "" {
+		defaultFeeRecipient = query.DefaultFeeRecipient
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+	c.JSON(http.StatusOK, services.BuildProposerConfig(defaultFeeRecipient, validatorRequest.Keys))
+}
+
+func (h *Handler) GetProposerConfig(c *gin.Context) {
+	query, fileName, ok := bindProposerConfigQuery(c)
+	if !ok {
+		return
+	}
+
+	// validator clients require a default fee recipient, there is no request to take it from
+	if query.DefaultFeeRecipient == "" {
+		log.Println(ErrInvalidDefaultFeeRecipient)
+		c.JSON(http.StatusBadRequest, &ErrorResponse{Error: ErrInvalidDefaultFeeRecipient})
+		return
+	}
+
+	validatorKeys, err := repository.GetAllValidatorKeyFeeRecipients(h.db, middlewares.TenantID(c))
+	if err != nil {
+		log.Printf("%s, error: %v", ErrLoadingProposerConfig, err)
+		c.JSON(http.StatusInternalServerError, &ErrorResponse{Error: ErrInternalServer})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+	c.JSON(http.StatusOK, services.BuildProposerConfig(query.DefaultFeeRecipient, validatorKeys))
+}
+
+// bindProposerConfigQuery validates the query and returns the file name of the requested format.
+func bindProposerConfigQuery(c *gin.Context) (*ProposerConfigQuery, string, bool) {
+	query := ProposerConfigQuery{Format: eth2.ProposerConfigTeku}
+	if err := c.ShouldBindQuery(&query); err != nil {
+		log.Println(ErrInvalidRequestBody, err)
+		c.JSON(http.StatusBadRequest, &ErrorResponse{Error: ErrInvalidRequestBody})
+		return nil, "", false
+	}
+
+	fileName, err := eth2.ProposerConfigFileName(query.Format)
+	if err != nil {
+		log.Printf("%s, format: %s", ErrInvalidProposerConfigFormat, query.Format)
+		c.JSON(http.StatusBadRequest, &ErrorResponse{Error: ErrInvalidProposerConfigFormat})
+		return nil, "", false
+	}
+
+	if query.DefaultFeeRecipient != "" && !utils.ValidateAddress(query.DefaultFeeRecipient) {
+		log.Println(ErrInvalidDefaultFeeRecipient)
+		c.JSON(http.StatusBadRequest, &ErrorResponse{Error: ErrInvalidDefaultFeeRecipient})
+		return nil, "", false
+	}
+
+	return &query, fileName, true
+}
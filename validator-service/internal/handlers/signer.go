@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"encoding/hex"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"log"
+	"net/http"
+	"strings"
+	"validator-service/internal/eth2"
+	"validator-service/internal/middlewares"
+	"validator-service/internal/repository"
+	"validator-service/internal/services"
+	"validator-service/internal/slashing"
+)
+
+const (
+	ErrInvalidSigningRequest = "Invalid signing request"
+	ErrSigningMessage        = "Failed to sign message"
+	ErrListingPublicKeys     = "Failed to list public keys"
+	ErrSlashableMessage      = "Refused by slashing protection"
+)
+
+type SignResponse struct {
+	Signature string `json:"signature"`
+}
+
+// signingRequestErrors are the errors of signing requests that can not be signed as sent.
+var signingRequestErrors = []error{
+	eth2.ErrUnsupportedSignType,
+	eth2.ErrMissingSignData,
+	eth2.ErrInvalidSignData,
+	eth2.ErrMissingForkInfo,
+	eth2.ErrMissingBlockHeader,
+	eth2.ErrMissingCommitteeBits,
+	eth2.ErrUnknownFork,
+	eth2.ErrSigningRootMismatch,
+	eth2.ErrGenesisValidatorsRootMismatch,
+	eth2.ErrInvalidBitlist,
+}
+
+// ListPublicKeys returns the public keys available for signing, like Web3Signer.
+func (h *Handler) ListPublicKeys(c *gin.Context) {
+	pubkeys, err := repository.GetValidatorKeyPubkeys(h.db, middlewares.TenantID(c))
+	if err != nil {
+		log.Printf("%s, error: %v", ErrListingPublicKeys, err)
+		c.JSON(http.StatusInternalServerError, &ErrorResponse{Error: ErrInternalServer})
+		return
+	}
+
+	c.JSON(http.StatusOK, pubkeys)
+}
+
+// Sign signs a consensus message with the key of the pubkey path parameter, like Web3Signer.
+// The signature is returned as text unless the client accepts JSON.
+func (h *Handler) Sign(c *gin.Context) {
+	pubkey, ok := parsePubkeyParam(c)
+	if !ok {
+		return
+	}
+
+	var request eth2.SigningRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		log.Println(ErrInvalidSigningRequest, err)
+		c.JSON(http.StatusBadRequest, &ErrorResponse{Error: ErrInvalidSigningRequest})
+		return
+	}
+
+	signature, err := services.SignMessage(h.db, middlewares.TenantID(c), pubkey, &request, h.config.Network)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Printf("%s, pubkey: %s", ErrValidatorKeyNotFound, pubkey)
+		c.JSON(http.StatusNotFound, &ErrorResponse{Error: ErrValidatorKeyNotFound})
+		return
+	}
+	if errors.Is(err, slashing.ErrNotSafeToSign) {
+		log.Printf("%s, pubkey: %s, type: %s, error: %v", ErrSlashableMessage, pubkey, request.Type, err)
+		c.JSON(http.StatusPreconditionFailed, &ErrorResponse{Error: ErrSlashableMessage})
+		return
+	}
+	if isSigningRequestError(err) {
+		log.Printf("%s, pubkey: %s, type: %s, error: %v", ErrInvalidSigningRequest, pubkey, request.Type, err)
+		c.JSON(http.StatusBadRequest, &ErrorResponse{Error: ErrInvalidSigningRequest + ": " + err.Error()})
+		return
+	}
+	if err != nil {
+		log.Printf("%s, pubkey: %s, type: %s, error: %v", ErrSigningMessage, pubkey, request.Type, err)
+		c.JSON(http.StatusInternalServerError, &ErrorResponse{Error: ErrInternalServer})
+		return
+	}
+
+	encoded := "0x" + hex.EncodeToString(signature[:])
+	if strings.Contains(c.GetHeader("Accept"), gin.MIMEJSON) {
+		c.JSON(http.StatusOK, &SignResponse{Signature: encoded})
+		return
+	}
+
+	c.String(http.StatusOK, encoded)
+}
+
+// Upcheck reports that the signer is up, validator clients call it before using a remote signer.
+func (h *Handler) Upcheck(c *gin.Context) {
+	c.String(http.StatusOK, "OK")
+}
+
+func isSigningRequestError(err error) bool {
+	for _, requestErr := range signingRequestErrors {
+		if errors.Is(err, requestErr) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"log"
+	"net/http"
+	"validator-service/internal/eth2"
+	"validator-service/internal/middlewares"
+	"validator-service/internal/slashing"
+)
+
+const (
+	ErrInvalidInterchange           = "Invalid slashing protection interchange"
+	ErrImportingSlashingProtection  = "Failed to import slashing protection data"
+	ErrExportingSlashingProtection  = "Failed to export slashing protection data"
+	ErrInterchangeNetworkMismatch   = "Interchange is for another network"
+	ErrUnsupportedInterchangeFormat = "Unsupported interchange format version, expected " + slashing.InterchangeFormatVersion
+)
+
+// ExportSlashingProtection returns the signing history of all keys in the EIP-3076 interchange format.
+func (h *Handler) ExportSlashingProtection(c *gin.Context) {
+	interchange, err := slashing.Export(h.db, middlewares.TenantID(c), h.config.Network)
+	if err != nil {
+		log.Printf("%s, error: %v", ErrExportingSlashingProtection, err)
+		c.JSON(http.StatusInternalServerError, &ErrorResponse{Error: ErrInternalServer})
+		return
+	}
+
+	c.JSON(http.StatusOK, interchange)
+}
+
+// ImportSlashingProtection imports an EIP-3076 interchange, e.g. exported by the signer that used the keys before.
+func (h *Handler) ImportSlashingProtection(c *gin.Context) {
+	var interchange slashing.Interchange
+	if err := c.ShouldBindJSON(&interchange); err != nil {
+		log.Println(ErrInvalidInterchange, err)
+		c.JSON(http.StatusBadRequest, &ErrorResponse{Error: ErrInvalidInterchange})
+		return
+	}
+
+	result, err := slashing.Import(h.db, middlewares.TenantID(c), &interchange, h.config.Network)
+	if errors.Is(err, slashing.ErrUnsupportedInterchangeVersion) {
+		log.Printf("%s, version: %s", ErrUnsupportedInterchangeFormat, interchange.Metadata.InterchangeFormatVersion)
+		c.JSON(http.StatusBadRequest, &ErrorResponse{Error: ErrUnsupportedInterchangeFormat})
+		return
+	}
+	if errors.Is(err, eth2.ErrGenesisValidatorsRootMismatch) {
+		log.Println(ErrInterchangeNetworkMismatch)
+		c.JSON(http.StatusBadRequest, &ErrorResponse{Error: ErrInterchangeNetworkMismatch})
+		return
+	}
+	if err != nil {
+		log.Printf("%s, error: %v", ErrImportingSlashingProtection, err)
+		c.JSON(http.StatusInternalServerError, &ErrorResponse{Error: ErrInternalServer})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"encoding/hex"
+	"github.com/gin-gonic/gin"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+	"validator-service/internal/keys"
+	"validator-service/internal/middlewares"
+	"validator-service/internal/repository"
+)
+
+const (
+	ErrInvalidPubkey        = "Invalid validator public key"
+	ErrValidatorKeyNotFound = "Validator key not found"
+)
+
+type ValidatorKeyResponse struct {
+	Pubkey                string                  `json:"pubkey"`
+	DerivationIndex       uint                    `json:"derivation_index"`
+	WithdrawalCredentials string                  `json:"withdrawal_credentials"`
+	WithdrawalPubkey      string                  `json:"withdrawal_pubkey,omitempty"`
+	FeeRecipient          string                  `json:"fee_recipient"`
+	CreatedAt             time.Time               `json:"created_at"`
+	Request               ValidatorRequestSummary `json:"request"`
+}
+
+func (h *Handler) GetValidatorKey(c *gin.Context) {
+	pubkey, ok := parsePubkeyParam(c)
+	if !ok {
+		return
+	}
+
+	validatorKey, err := repository.GetValidatorKeyByPubkeyWithoutSecrets(h.db, middlewares.TenantID(c), pubkey)
+	if err != nil {
+		log.Printf("%s, pubkey: %s", ErrValidatorKeyNotFound, pubkey)
+		c.JSON(http.StatusNotFound, &ErrorResponse{Error: ErrValidatorKeyNotFound})
+		return
+	}
+
+	validatorRequest, err := repository.GetValidatorRequestByIDWithoutKeys(h.db, validatorKey.ValidatorRequestID)
+	if err != nil {
+		log.Printf("%s, pubkey: %s", ErrRequestNotFound, pubkey)
+		c.JSON(http.StatusNotFound, &ErrorResponse{Error: ErrRequestNotFound})
+		return
+	}
+
+	c.JSON(http.StatusOK, &ValidatorKeyResponse{
+		Pubkey:                validatorKey.Key,
+		DerivationIndex:       validatorKey.DerivationIndex,
+		WithdrawalCredentials: validatorKey.WithdrawalCredentials,
+		WithdrawalPubkey:      validatorKey.WithdrawalPubkey,
+		FeeRecipient:          validatorKey.FeeRecipient,
+		CreatedAt:             validatorKey.CreatedAt,
+		Request:               toValidatorRequestSummary(validatorRequest),
+	})
+}
+
+// parsePubkeyParam returns the pubkey path parameter in the stored form, 0x prefixed lowercase hex.
+// It accepts keys without prefix or in upper case as shown by some beacon chain explorers.
+func parsePubkeyParam(c *gin.Context) (string, bool) {
+	pubkey, err := keys.ParsePublicKey(strings.ToLower(c.Param("pubkey")))
+	if err != nil {
+		log.Printf("%s, pubkey: %s", ErrInvalidPubkey, c.Param("pubkey"))
+		c.JSON(http.StatusBadRequest, &ErrorResponse{Error: ErrInvalidPubkey})
+		return "", false
+	}
+
+	serialized := pubkey.Serialize()
+	return "0x" + hex.EncodeToString(serialized[:]), true
+}
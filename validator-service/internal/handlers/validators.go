@@ -1,14 +1,23 @@
 package handlers
 
 import (
+	"encoding/base64"
+	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 	"log"
 	"net/http"
+	"strconv"
 	"sync"
+	"time"
+	"validator-service/internal/config"
+	"validator-service/internal/events"
+	"validator-service/internal/keys"
+	"validator-service/internal/middlewares"
 	"validator-service/internal/models"
+	"validator-service/internal/queue"
 	"validator-service/internal/repository"
 	"validator-service/internal/services"
 	"validator-service/internal/utils"
@@ -16,32 +25,72 @@ import (
 
 const MinNumberOfValidators = 0
 
+const (
+	DefaultListLimit = 50
+	MaxListLimit     = 100
+)
+
+// QueueFullRetryAfter is the number of seconds clients are asked to wait when the job queue is full.
+const QueueFullRetryAfter = 30
+
 const (
 	ErrInvalidRequestBody        = "Invalid request body"
 	ErrInvalidNumberOfValidators = "Invalid number of validators"
 	ErrInvalidFeeRecipient       = "Invalid fee recipient address"
+	ErrInvalidWithdrawalAddress  = "Invalid withdrawal address"
 	ErrInternalServer            = "Internal server error"
 	ErrCreatingValidator         = "Failed to create validator"
 	ErrRequestNotFound           = "Request not found"
 	ErrProcessingRequest         = "Error processing request"
+	ErrGeneratingMnemonic        = "Failed to generate mnemonic"
+	ErrRequestNotCompleted       = "Request is not completed"
+	ErrTooManyValidators         = "Too many validators in request"
+	ErrQueueFull                 = "Too many requests in progress, try again later"
+	ErrRequestNotInProgress      = "Request is not in progress"
+	ErrCancellingRequest         = "Failed to cancel request"
+	ErrInvalidStatus             = "Invalid status"
+	ErrInvalidCreatedAfter       = "Invalid created_after, expected an RFC 3339 time"
+	ErrInvalidCreatedBefore      = "Invalid created_before, expected an RFC 3339 time"
+	ErrInvalidLimit              = "Invalid limit"
+	ErrInvalidCursor             = "Invalid cursor"
+	ErrListingRequests           = "Failed to list validator requests"
+	ErrInvalidCallbackURL        = "Invalid callback URL, expected an http or https URL"
+	ErrPrivateCallbackURL        = "Callback URLs on loopback, private or link-local addresses are not allowed"
+	ErrWebhooksNotConfigured     = "Callback URLs are not supported, webhooks are not configured"
 
 	ValidatorCreationInProgress = "Validator creation in progress"
+	ValidatorCreationCancelled  = "Validator creation cancelled"
 )
 
 type Handler struct {
-	db          *gorm.DB
-	requestLock sync.Mutex
+	db     *gorm.DB
+	config *config.Config
+	queue  *queue.Pool
+	events *events.Bus
+	// streamsClosed is closed by CloseStreams to end the event streams on shutdown.
+	streamsClosed chan struct{}
+	closeStreams  sync.Once
 }
 
-func CreateNewHandler(db *gorm.DB) *Handler {
+func CreateNewHandler(db *gorm.DB, cfg *config.Config, pool *queue.Pool, bus *events.Bus) *Handler {
 	return &Handler{
-		db: db,
+		db:            db,
+		config:        cfg,
+		queue:         pool,
+		events:        bus,
+		streamsClosed: make(chan struct{}),
 	}
 }
 
 type CreateValidatorRequest struct {
-	NumValidators uint   `json:"num_validators"`
-	FeeRecipient  string `json:"fee_recipient"`
+	NumValidators     uint   `json:"num_validators"`
+	FeeRecipient      string `json:"fee_recipient"`
+	WithdrawalAddress string `json:"withdrawal_address"`
+	CallbackURL       string `json:"callback_url"`
+}
+
+type AddValidatorsRequest struct {
+	NumValidators uint `json:"num_validators"`
 }
 
 type CreateValidatorResponse struct {
@@ -50,8 +99,10 @@ type CreateValidatorResponse struct {
 }
 
 type ValidatorStatusResponse struct {
-	Status models.RequestStatus `json:"status"`
-	Keys   []string             `json:"keys"`
+	Status        models.RequestStatus `json:"status"`
+	KeysGenerated uint                 `json:"keys_generated"`
+	KeysTotal     uint                 `json:"keys_total"`
+	Keys          []string             `json:"keys"`
 }
 
 type ErrValidatorStatusResponse struct {
@@ -59,6 +110,30 @@ type ErrValidatorStatusResponse struct {
 	Message string
 }
 
+type ListValidatorRequestsQuery struct {
+	Status        string `form:"status"`
+	FeeRecipient  string `form:"fee_recipient"`
+	CreatedAfter  string `form:"created_after"`
+	CreatedBefore string `form:"created_before"`
+	Limit         int    `form:"limit"`
+	Cursor        string `form:"cursor"`
+}
+
+type ValidatorRequestSummary struct {
+	RequestId         string               `json:"request_id"`
+	Status            models.RequestStatus `json:"status"`
+	NumValidators     uint                 `json:"num_validators"`
+	FeeRecipient      string               `json:"fee_recipient"`
+	WithdrawalAddress string               `json:"withdrawal_address,omitempty"`
+	CallbackURL       string               `json:"callback_url,omitempty"`
+	CreatedAt         time.Time            `json:"created_at"`
+}
+
+type ListValidatorRequestsResponse struct {
+	Requests   []ValidatorRequestSummary `json:"requests"`
+	NextCursor string                    `json:"next_cursor,omitempty"`
+}
+
 type ErrorResponse struct {
 	Error string `json:"error"`
 }
@@ -77,20 +152,107 @@ func (h *Handler) CreateValidator(c *gin.Context) {
 		return
 	}
 
+	if req.NumValidators > h.config.MaxValidatorsPerRequest {
+		log.Printf("%s, num_validators: %d", ErrTooManyValidators, req.NumValidators)
+		c.JSON(http.StatusBadRequest, &ErrorResponse{Error: ErrTooManyValidators})
+		return
+	}
+
 	if utils.ValidateAddress(req.FeeRecipient) != true {
 		log.Println(ErrInvalidFeeRecipient)
 		c.JSON(http.StatusBadRequest, &ErrorResponse{Error: ErrInvalidFeeRecipient})
 		return
 	}
 
+	if req.WithdrawalAddress != "" && !utils.ValidateAddress(req.WithdrawalAddress) {
+		log.Println(ErrInvalidWithdrawalAddress)
+		c.JSON(http.StatusBadRequest, &ErrorResponse{Error: ErrInvalidWithdrawalAddress})
+		return
+	}
+
+	if req.CallbackURL != "" && !utils.ValidateURL(req.CallbackURL) {
+		log.Println(ErrInvalidCallbackURL)
+		c.JSON(http.StatusBadRequest, &ErrorResponse{Error: ErrInvalidCallbackURL})
+		return
+	}
+
+	if req.CallbackURL != "" && !h.config.WebhookAllowPrivateNetworks && utils.IsPrivateURL(req.CallbackURL) {
+		log.Println(ErrPrivateCallbackURL)
+		c.JSON(http.StatusBadRequest, &ErrorResponse{Error: ErrPrivateCallbackURL})
+		return
+	}
+
+	if req.CallbackURL != "" && h.config.WebhookSecret == "" {
+		log.Println(ErrWebhooksNotConfigured)
+		c.JSON(http.StatusBadRequest, &ErrorResponse{Error: ErrWebhooksNotConfigured})
+		return
+	}
+
+	idempotencyKey := c.GetHeader(IdempotencyKeyHeader)
+	if len(idempotencyKey) > MaxIdempotencyKeyLength {
+		log.Println(ErrInvalidIdempotencyKey)
+		c.JSON(http.StatusBadRequest, &ErrorResponse{Error: ErrInvalidIdempotencyKey})
+		return
+	}
+
+	requestHash := idempotencyRequestHash(&req)
+	if idempotencyKey != "" && h.replayIdempotentRequest(c, idempotencyKey, requestHash) {
+		return
+	}
+
+	if h.rejectWhenQueueFull(c) {
+		return
+	}
+
+	mnemonic, err := keys.GenerateMnemonic()
+	if err != nil {
+		log.Printf("%s. %s. Error: %v", ErrGeneratingMnemonic, ErrInternalServer, err)
+		c.JSON(http.StatusInternalServerError, &ErrorResponse{Error: ErrInternalServer})
+		return
+	}
+
 	validatorRequest := models.ValidatorRequest{
-		RequestUUID:   uuid.New().String(),
-		NumValidators: req.NumValidators,
-		FeeRecipient:  req.FeeRecipient,
-		Status:        models.RequestStarted,
+		TenantID:          middlewares.TenantID(c),
+		RequestUUID:       uuid.New().String(),
+		NumValidators:     req.NumValidators,
+		FeeRecipient:      req.FeeRecipient,
+		WithdrawalAddress: req.WithdrawalAddress,
+		CallbackURL:       req.CallbackURL,
+		Mnemonic:          mnemonic,
+		Status:            models.RequestStarted,
+		StartedAt:         time.Now(),
+	}
+
+	response := &CreateValidatorResponse{
+		RequestId: validatorRequest.RequestUUID,
+		Message:   ValidatorCreationInProgress,
 	}
 
-	err := repository.CreateValidatorRequest(h.db, &validatorRequest)
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		if err := repository.CreateValidatorRequest(tx, &validatorRequest); err != nil {
+			return err
+		}
+
+		if err := services.EnqueueValidatorRequest(tx, &validatorRequest, h.config.JobMaxAttempts); err != nil {
+			return err
+		}
+
+		if idempotencyKey == "" {
+			return nil
+		}
+
+		key, err := h.newIdempotencyKey(idempotencyKey, requestHash, &validatorRequest, http.StatusOK, response)
+		if err != nil {
+			return err
+		}
+
+		return repository.CreateIdempotencyKey(tx, key)
+	})
+
+	// a concurrent request with the same idempotency key was stored first
+	if errors.Is(err, gorm.ErrDuplicatedKey) && h.replayIdempotentRequest(c, idempotencyKey, requestHash) {
+		return
+	}
 
 	if err != nil {
 		log.Printf("%s. %s. Error: %v", ErrCreatingValidator, ErrInternalServer, err)
@@ -98,7 +260,75 @@ func (h *Handler) CreateValidator(c *gin.Context) {
 		return
 	}
 
-	go services.ProcessValidatorRequest(h.db, &validatorRequest, &h.requestLock)
+	h.queue.Notify()
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *Handler) AddValidators(c *gin.Context) {
+	reqID := c.Param("request_id")
+
+	var req AddValidatorsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Println(ErrInvalidRequestBody, err)
+		c.JSON(http.StatusBadRequest, &ErrorResponse{Error: ErrInvalidRequestBody})
+		return
+	}
+
+	if req.NumValidators <= MinNumberOfValidators {
+		log.Println(ErrInvalidNumberOfValidators)
+		c.JSON(http.StatusBadRequest, &ErrorResponse{Error: ErrInvalidNumberOfValidators})
+		return
+	}
+
+	validatorRequest, err := repository.GetValidatorRequestByUUIDWithoutKeys(h.db, middlewares.TenantID(c), reqID)
+	if err != nil {
+		log.Printf("%s, request_id: %s", ErrRequestNotFound, reqID)
+		c.JSON(http.StatusNotFound, &ErrorResponse{Error: ErrRequestNotFound})
+		return
+	}
+
+	if validatorRequest.Status != models.RequestSuccessful {
+		log.Printf("%s, request_id: %s, status: %s", ErrRequestNotCompleted, reqID, validatorRequest.Status)
+		c.JSON(http.StatusConflict, &ErrorResponse{Error: ErrRequestNotCompleted})
+		return
+	}
+
+	if validatorRequest.NumValidators+req.NumValidators > h.config.MaxValidatorsPerRequest {
+		log.Printf("%s, request_id: %s, num_validators: %d", ErrTooManyValidators, reqID, validatorRequest.NumValidators+req.NumValidators)
+		c.JSON(http.StatusBadRequest, &ErrorResponse{Error: ErrTooManyValidators})
+		return
+	}
+
+	if h.rejectWhenQueueFull(c) {
+		return
+	}
+
+	// the status is checked again by the update, only one of concurrent additions starts the request
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		added, err := repository.AddValidatorsToRequest(tx, validatorRequest, req.NumValidators)
+		if err != nil {
+			return err
+		}
+		if !added {
+			return repository.ErrStatusChanged
+		}
+
+		return services.EnqueueValidatorRequest(tx, validatorRequest, h.config.JobMaxAttempts)
+	})
+
+	if errors.Is(err, repository.ErrStatusChanged) {
+		log.Printf("%s, request_id: %s", ErrRequestNotCompleted, reqID)
+		c.JSON(http.StatusConflict, &ErrorResponse{Error: ErrRequestNotCompleted})
+		return
+	}
+	if err != nil {
+		log.Printf("%s. %s. Error: %v", ErrCreatingValidator, ErrInternalServer, err)
+		c.JSON(http.StatusInternalServerError, &ErrorResponse{Error: ErrInternalServer})
+		return
+	}
+
+	h.queue.Notify()
 
 	c.JSON(http.StatusOK, &CreateValidatorResponse{
 		RequestId: validatorRequest.RequestUUID,
@@ -106,10 +336,122 @@ func (h *Handler) CreateValidator(c *gin.Context) {
 	})
 }
 
+func (h *Handler) CancelValidatorRequest(c *gin.Context) {
+	reqID := c.Param("request_id")
+
+	validatorRequest, err := repository.GetValidatorRequestByUUIDWithoutKeys(h.db, middlewares.TenantID(c), reqID)
+	if err != nil {
+		log.Printf("%s, request_id: %s", ErrRequestNotFound, reqID)
+		c.JSON(http.StatusNotFound, &ErrorResponse{Error: ErrRequestNotFound})
+		return
+	}
+
+	err = services.CancelValidatorRequest(h.db, validatorRequest)
+	if errors.Is(err, services.ErrValidatorRequestNotInProgress) {
+		log.Printf("%s, request_id: %s, status: %s", ErrRequestNotInProgress, reqID, validatorRequest.Status)
+		c.JSON(http.StatusConflict, &ErrorResponse{Error: ErrRequestNotInProgress})
+		return
+	}
+	if err != nil {
+		log.Printf("%s, request_id: %s, error: %v", ErrCancellingRequest, reqID, err)
+		c.JSON(http.StatusInternalServerError, &ErrorResponse{Error: ErrInternalServer})
+		return
+	}
+
+	c.JSON(http.StatusOK, &CreateValidatorResponse{
+		RequestId: validatorRequest.RequestUUID,
+		Message:   ValidatorCreationCancelled,
+	})
+}
+
+func (h *Handler) ListValidatorRequests(c *gin.Context) {
+	var query ListValidatorRequestsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		log.Println(ErrInvalidRequestBody, err)
+		c.JSON(http.StatusBadRequest, &ErrorResponse{Error: ErrInvalidRequestBody})
+		return
+	}
+
+	filter := repository.ValidatorRequestFilter{
+		Status:       models.RequestStatus(query.Status),
+		FeeRecipient: query.FeeRecipient,
+		Limit:        DefaultListLimit,
+	}
+
+	if query.Status != "" && !isRequestStatus(filter.Status) {
+		log.Printf("%s, status: %s", ErrInvalidStatus, query.Status)
+		c.JSON(http.StatusBadRequest, &ErrorResponse{Error: ErrInvalidStatus})
+		return
+	}
+
+	if query.FeeRecipient != "" && !utils.ValidateAddress(query.FeeRecipient) {
+		log.Println(ErrInvalidFeeRecipient)
+		c.JSON(http.StatusBadRequest, &ErrorResponse{Error: ErrInvalidFeeRecipient})
+		return
+	}
+
+	var err error
+	if query.CreatedAfter != "" {
+		if filter.CreatedAfter, err = time.Parse(time.RFC3339, query.CreatedAfter); err != nil {
+			log.Println(ErrInvalidCreatedAfter, err)
+			c.JSON(http.StatusBadRequest, &ErrorResponse{Error: ErrInvalidCreatedAfter})
+			return
+		}
+	}
+
+	if query.CreatedBefore != "" {
+		if filter.CreatedBefore, err = time.Parse(time.RFC3339, query.CreatedBefore); err != nil {
+			log.Println(ErrInvalidCreatedBefore, err)
+			c.JSON(http.StatusBadRequest, &ErrorResponse{Error: ErrInvalidCreatedBefore})
+			return
+		}
+	}
+
+	if query.Limit != 0 {
+		if query.Limit < 0 || query.Limit > MaxListLimit {
+			log.Printf("%s, limit: %d", ErrInvalidLimit, query.Limit)
+			c.JSON(http.StatusBadRequest, &ErrorResponse{Error: ErrInvalidLimit})
+			return
+		}
+		filter.Limit = query.Limit
+	}
+
+	if query.Cursor != "" {
+		if filter.BeforeID, err = decodeCursor(query.Cursor); err != nil {
+			log.Println(ErrInvalidCursor, err)
+			c.JSON(http.StatusBadRequest, &ErrorResponse{Error: ErrInvalidCursor})
+			return
+		}
+	}
+
+	// one more request than the limit tells whether there is a next page
+	limit := filter.Limit
+	filter.Limit++
+
+	validatorRequests, err := repository.ListValidatorRequests(h.db, middlewares.TenantID(c), filter)
+	if err != nil {
+		log.Printf("%s. %s. Error: %v", ErrListingRequests, ErrInternalServer, err)
+		c.JSON(http.StatusInternalServerError, &ErrorResponse{Error: ErrInternalServer})
+		return
+	}
+
+	response := &ListValidatorRequestsResponse{Requests: []ValidatorRequestSummary{}}
+	if len(validatorRequests) > limit {
+		validatorRequests = validatorRequests[:limit]
+		response.NextCursor = encodeCursor(validatorRequests[limit-1].ID)
+	}
+
+	for i := range validatorRequests {
+		response.Requests = append(response.Requests, toValidatorRequestSummary(&validatorRequests[i]))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
 func (h *Handler) CheckRequestStatus(c *gin.Context) {
 	reqID := c.Param("request_id")
 	fmt.Println(reqID)
-	validatorRequest, err := repository.GetValidatorRequestByUUID(h.db, reqID)
+	validatorRequest, err := repository.GetValidatorRequestByUUIDWithPublicKeys(h.db, middlewares.TenantID(c), reqID)
 
 	if err != nil {
 		log.Printf("%s, request_id: %s", ErrRequestNotFound, reqID)
@@ -128,6 +470,25 @@ func (h *Handler) CheckRequestStatus(c *gin.Context) {
 	c.JSON(http.StatusOK, h.toValidatorStatusResponse(validatorRequest))
 }
 
+func (h *Handler) rejectWhenQueueFull(c *gin.Context) bool {
+	// webhooks are not counted, slow callback URLs must not keep requests from being created
+	activeJobs, err := repository.CountActiveJobs(h.db, services.ValidatorRequestJobKind)
+	if err != nil {
+		log.Printf("%s. Error: %v", ErrInternalServer, err)
+		c.JSON(http.StatusInternalServerError, &ErrorResponse{Error: ErrInternalServer})
+		return true
+	}
+
+	if activeJobs >= int64(h.config.MaxQueueDepth) {
+		log.Printf("%s, active_jobs: %d", ErrQueueFull, activeJobs)
+		c.Header("Retry-After", strconv.Itoa(QueueFullRetryAfter))
+		c.JSON(http.StatusTooManyRequests, &ErrorResponse{Error: ErrQueueFull})
+		return true
+	}
+
+	return false
+}
+
 func (h *Handler) toValidatorStatusResponse(validatorRequest *models.ValidatorRequest) *ValidatorStatusResponse {
 	var keys []string
 
@@ -136,7 +497,49 @@ func (h *Handler) toValidatorStatusResponse(validatorRequest *models.ValidatorRe
 	}
 
 	return &ValidatorStatusResponse{
-		Status: validatorRequest.Status,
-		Keys:   keys,
+		Status:        validatorRequest.Status,
+		KeysGenerated: uint(len(validatorRequest.Keys)),
+		KeysTotal:     validatorRequest.NumValidators,
+		Keys:          keys,
+	}
+}
+
+func toValidatorRequestSummary(validatorRequest *models.ValidatorRequest) ValidatorRequestSummary {
+	return ValidatorRequestSummary{
+		RequestId:         validatorRequest.RequestUUID,
+		Status:            validatorRequest.Status,
+		NumValidators:     validatorRequest.NumValidators,
+		FeeRecipient:      validatorRequest.FeeRecipient,
+		WithdrawalAddress: validatorRequest.WithdrawalAddress,
+		CallbackURL:       validatorRequest.CallbackURL,
+		CreatedAt:         validatorRequest.CreatedAt,
+	}
+}
+
+func isRequestStatus(status models.RequestStatus) bool {
+	switch status {
+	case models.RequestStarted, models.RequestProcessing, models.RequestSuccessful, models.RequestFailed, models.RequestCancelled:
+		return true
 	}
+
+	return false
+}
+
+// encodeCursor returns an opaque cursor that continues a listing after the request with the given id.
+func encodeCursor(id uint) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatUint(uint64(id), 10)))
+}
+
+func decodeCursor(cursor string) (uint, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := strconv.ParseUint(string(decoded), 10, 64)
+	if err != nil || id == 0 {
+		return 0, errors.New("invalid cursor id")
+	}
+
+	return uint(id), nil
 }
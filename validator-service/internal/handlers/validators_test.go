@@ -0,0 +1,194 @@
+package handlers_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"validator-service/internal/config"
+	"validator-service/internal/eth2"
+	"validator-service/internal/events"
+	"validator-service/internal/handlers"
+	"validator-service/internal/middlewares"
+	"validator-service/internal/migrations"
+	"validator-service/internal/models"
+	"validator-service/internal/queue"
+	"validator-service/internal/repository"
+	"validator-service/internal/routers"
+	"validator-service/internal/services"
+)
+
+const (
+	testTenantID  uint = 1
+	otherTenantID uint = 2
+)
+
+// tenantIDHeader carries the tenant of test requests instead of an API key.
+const tenantIDHeader = "X-Tenant-ID"
+
+func setupTestDB(t *testing.T) *gorm.DB {
+	keyring, err := repository.NewKeyring(make([]byte, repository.MasterKeyLength))
+	assert.NoError(t, err)
+	repository.SetKeyring(keyring)
+
+	db, err := repository.OpenDatabase(repository.DriverSQLite, filepath.Join(t.TempDir(), "handlers.db"))
+	assert.NoError(t, err)
+
+	_, err = migrations.Up(db)
+	assert.NoError(t, err)
+
+	return db
+}
+
+// setupRouter routes all endpoints of a handler with an idle job queue. Requests are authenticated as an
+// admin of the tenant in the X-Tenant-ID header instead of with an API key.
+func setupRouter(t *testing.T, db *gorm.DB) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	network, err := eth2.GetNetwork(eth2.NetworkMainnet)
+	assert.NoError(t, err)
+
+	cfg := &config.Config{
+		Network:                 network,
+		JobMaxAttempts:          1,
+		MaxValidatorsPerRequest: 100,
+		MaxQueueDepth:           100,
+		IdempotencyKeyTTL:       time.Hour,
+	}
+	h := handlers.CreateNewHandler(db, cfg, queue.NewPool(db, queue.DefaultConfig()), events.NewBus())
+
+	r := gin.New()
+	routers.SetupRoutes(r, h, func(c *gin.Context) {
+		tenantID, _ := strconv.ParseUint(c.GetHeader(tenantIDHeader), 10, 64)
+		c.Set(middlewares.TenantIDKey, uint(tenantID))
+		c.Set(middlewares.RoleKey, models.RoleAdmin)
+	})
+
+	return r
+}
+
+func serve(r *gin.Engine, tenantID uint, method string, path string, body string) *httptest.ResponseRecorder {
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+
+	req := httptest.NewRequest(method, path, reader)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(tenantIDHeader, strconv.FormatUint(uint64(tenantID), 10))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+// newSuccessfulRequest stores a successful request of the test tenant with the public keys.
+func newSuccessfulRequest(t *testing.T, db *gorm.DB, feeRecipient string, pubkeys ...string) *models.ValidatorRequest {
+	validatorRequest := &models.ValidatorRequest{
+		TenantID:      testTenantID,
+		RequestUUID:   "test_uuid",
+		NumValidators: uint(len(pubkeys)),
+		FeeRecipient:  feeRecipient,
+		Status:        models.RequestSuccessful,
+	}
+	for i, pubkey := range pubkeys {
+		validatorRequest.Keys = append(validatorRequest.Keys, models.ValidatorKey{
+			Key:             pubkey,
+			FeeRecipient:    feeRecipient,
+			DerivationIndex: uint(i),
+		})
+	}
+	assert.NoError(t, repository.CreateValidatorRequest(db, validatorRequest))
+
+	return validatorRequest
+}
+
+func countActiveJobs(t *testing.T, db *gorm.DB) int64 {
+	count, err := repository.CountActiveJobs(db, services.ValidatorRequestJobKind)
+	assert.NoError(t, err)
+	return count
+}
+
+func TestAddValidators(t *testing.T) {
+	db := setupTestDB(t)
+	r := setupRouter(t, db)
+	validatorRequest := newSuccessfulRequest(t, db, oldFeeRecipient, "0x01", "0x02")
+
+	w := serve(r, testTenantID, http.MethodPost, "/validators/"+validatorRequest.RequestUUID+"/keys", `{"num_validators": 3}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, validatorRequest.RequestUUID, requestID(t, w))
+
+	result, err := repository.GetValidatorRequestByID(db, validatorRequest.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.RequestStarted, result.Status)
+	assert.Equal(t, uint(5), result.NumValidators)
+	assert.Equal(t, int64(1), countActiveJobs(t, db))
+
+	// the request is in progress again
+	w = serve(r, testTenantID, http.MethodPost, "/validators/"+validatorRequest.RequestUUID+"/keys", `{"num_validators": 3}`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), handlers.ErrRequestNotCompleted)
+}
+
+// loadTogether holds the first n loads of validator requests until all of them were made, so that
+// concurrent requests all see the same state before any of them changes it.
+func loadTogether(t *testing.T, db *gorm.DB, n int32) {
+	var loads atomic.Int32
+	var loaded sync.WaitGroup
+	loaded.Add(int(n))
+
+	name := "test:load_together"
+	err := db.Callback().Query().After("gorm:query").Register(name, func(tx *gorm.DB) {
+		if tx.Statement.Table == "validator_requests" && loads.Add(1) <= n {
+			loaded.Done()
+			loaded.Wait()
+		}
+	})
+	assert.NoError(t, err)
+
+	t.Cleanup(func() {
+		db.Callback().Query().Remove(name)
+	})
+}
+
+func TestAddValidatorsConcurrently(t *testing.T) {
+	db := setupTestDB(t)
+	r := setupRouter(t, db)
+	validatorRequest := newSuccessfulRequest(t, db, oldFeeRecipient, "0x01", "0x02")
+
+	const additions = 5
+	loadTogether(t, db, additions)
+	codes := make(chan int, additions)
+	var wg sync.WaitGroup
+	for i := 0; i < additions; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			codes <- serve(r, testTenantID, http.MethodPost, "/validators/"+validatorRequest.RequestUUID+"/keys", `{"num_validators": 3}`).Code
+		}()
+	}
+	wg.Wait()
+	close(codes)
+
+	counts := map[int]int{}
+	for code := range codes {
+		counts[code]++
+	}
+	assert.Equal(t, map[int]int{http.StatusOK: 1, http.StatusConflict: additions - 1}, counts)
+
+	// exactly one addition was applied and queued
+	result, err := repository.GetValidatorRequestByID(db, validatorRequest.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(5), result.NumValidators)
+	assert.Equal(t, int64(1), countActiveJobs(t, db))
+}
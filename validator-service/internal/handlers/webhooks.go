@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"log"
+	"net/http"
+	"time"
+	"validator-service/internal/middlewares"
+	"validator-service/internal/repository"
+)
+
+const ErrLoadingWebhookDeliveries = "Failed to load webhook deliveries"
+
+type WebhookDeliveryResponse struct {
+	Id          uint      `json:"id"`
+	Event       string    `json:"event"`
+	URL         string    `json:"url"`
+	Attempt     uint      `json:"attempt"`
+	StatusCode  int       `json:"status_code,omitempty"`
+	Error       string    `json:"error,omitempty"`
+	Delivered   bool      `json:"delivered"`
+	AttemptedAt time.Time `json:"attempted_at"`
+}
+
+func (h *Handler) GetWebhookDeliveries(c *gin.Context) {
+	reqID := c.Param("request_id")
+
+	validatorRequest, err := repository.GetValidatorRequestByUUIDWithoutKeys(h.db, middlewares.TenantID(c), reqID)
+	if err != nil {
+		log.Printf("%s, request_id: %s", ErrRequestNotFound, reqID)
+		c.JSON(http.StatusNotFound, &ErrorResponse{Error: ErrRequestNotFound})
+		return
+	}
+
+	deliveries, err := repository.GetWebhookDeliveries(h.db, validatorRequest.ID)
+	if err != nil {
+		log.Printf("%s, request_id: %s, error: %v", ErrLoadingWebhookDeliveries, reqID, err)
+		c.JSON(http.StatusInternalServerError, &ErrorResponse{Error: ErrInternalServer})
+		return
+	}
+
+	response := []WebhookDeliveryResponse{}
+	for _, delivery := range deliveries {
+		response = append(response, WebhookDeliveryResponse{
+			Id:          delivery.JobID,
+			Event:       delivery.Event,
+			URL:         delivery.URL,
+			Attempt:     delivery.Attempt,
+			StatusCode:  delivery.StatusCode,
+			Error:       delivery.Error,
+			Delivered:   delivery.Error == "",
+			AttemptedAt: delivery.CreatedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"log"
+	"net/http"
+	"validator-service/internal/eth2"
+	"validator-service/internal/middlewares"
+	"validator-service/internal/repository"
+	"validator-service/internal/services"
+	"validator-service/internal/utils"
+)
+
+const (
+	ErrInvalidExecutionAddress     = "Invalid execution address"
+	ErrNotBLSWithdrawalCredentials = "Validator does not have BLS withdrawal credentials"
+	ErrWithdrawalKeyNotAvailable   = "Withdrawal key of the validator is not available"
+	ErrSigningBLSToExecutionChange = "Failed to sign BLS to execution change"
+	ErrLoadingValidatorRequest     = "Failed to load request of validator key"
+)
+
+type BLSToExecutionChangeRequest struct {
+	ValidatorIndex     *eth2.Uint64 `json:"validator_index"`
+	ToExecutionAddress string       `json:"to_execution_address"`
+}
+
+func (h *Handler) CreateBLSToExecutionChange(c *gin.Context) {
+	pubkey, ok := parsePubkeyParam(c)
+	if !ok {
+		return
+	}
+
+	var req BLSToExecutionChangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Println(ErrInvalidRequestBody, err)
+		c.JSON(http.StatusBadRequest, &ErrorResponse{Error: ErrInvalidRequestBody})
+		return
+	}
+
+	if req.ValidatorIndex == nil {
+		log.Printf("%s, pubkey: %s", ErrMissingValidatorIndex, pubkey)
+		c.JSON(http.StatusBadRequest, &ErrorResponse{Error: ErrMissingValidatorIndex})
+		return
+	}
+
+	if !utils.ValidateAddress(req.ToExecutionAddress) {
+		log.Printf("%s, pubkey: %s", ErrInvalidExecutionAddress, pubkey)
+		c.JSON(http.StatusBadRequest, &ErrorResponse{Error: ErrInvalidExecutionAddress})
+		return
+	}
+
+	validatorKey, err := repository.GetValidatorKeyByPubkey(h.db, middlewares.TenantID(c), pubkey)
+	if err != nil {
+		log.Printf("%s, pubkey: %s", ErrValidatorKeyNotFound, pubkey)
+		c.JSON(http.StatusNotFound, &ErrorResponse{Error: ErrValidatorKeyNotFound})
+		return
+	}
+
+	validatorRequest, err := repository.GetValidatorRequestByID(h.db, validatorKey.ValidatorRequestID)
+	if err != nil {
+		log.Printf("%s, pubkey: %s, error: %v", ErrLoadingValidatorRequest, pubkey, err)
+		c.JSON(http.StatusInternalServerError, &ErrorResponse{Error: ErrInternalServer})
+		return
+	}
+
+	change, err := services.SignBLSToExecutionChange(validatorRequest, validatorKey, uint64(*req.ValidatorIndex), req.ToExecutionAddress, h.config.Network)
+	if errors.Is(err, services.ErrNotBLSWithdrawalCredentials) {
+		log.Printf("%s, pubkey: %s", ErrNotBLSWithdrawalCredentials, pubkey)
+		c.JSON(http.StatusConflict, &ErrorResponse{Error: ErrNotBLSWithdrawalCredentials})
+		return
+	}
+	if errors.Is(err, services.ErrWithdrawalKeyMismatch) {
+		log.Printf("%s, pubkey: %s", ErrWithdrawalKeyNotAvailable, pubkey)
+		c.JSON(http.StatusConflict, &ErrorResponse{Error: ErrWithdrawalKeyNotAvailable})
+		return
+	}
+	if err != nil {
+		log.Printf("%s, pubkey: %s, error: %v", ErrSigningBLSToExecutionChange, pubkey, err)
+		c.JSON(http.StatusInternalServerError, &ErrorResponse{Error: ErrInternalServer})
+		return
+	}
+
+	c.JSON(http.StatusOK, change)
+}
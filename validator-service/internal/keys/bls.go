@@ -0,0 +1,149 @@
+package keys
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"math/big"
+	"strings"
+
+	blsu "github.com/protolambda/bls12-381-util"
+	"golang.org/x/crypto/hkdf"
+)
+
+const (
+	PublicKeyLength = 48
+	SecretKeyLength = 32
+	SignatureLength = 96
+
+	keyGenSalt = "BLS-SIG-KEYGEN-SALT-"
+	ikmLength  = 32
+)
+
+var (
+	ErrInvalidSecretKey = errors.New("invalid BLS secret key")
+	ErrInvalidPublicKey = errors.New("invalid BLS public key")
+)
+
+// curveOrder is the order r of the BLS12-381 G1/G2 subgroups.
+var curveOrder, _ = new(big.Int).SetString("73eda753299d7d483339d80809a1d80553bda402fffe5bfeffffffff00000001", 16)
+
+// Keypair is a BLS12-381 secret key together with its public key.
+type Keypair struct {
+	Secret *blsu.SecretKey
+	Public *blsu.Pubkey
+}
+
+// GenerateKeypair creates a keypair from 32 bytes of fresh randomness using the
+// KeyGen procedure of the IETF BLS signature draft.
+func GenerateKeypair() (*Keypair, error) {
+	ikm := make([]byte, ikmLength)
+	if _, err := io.ReadFull(rand.Reader, ikm); err != nil {
+		return nil, err
+	}
+
+	return KeypairFromIKM(ikm)
+}
+
+// KeypairFromIKM deterministically derives a keypair from input key material.
+func KeypairFromIKM(ikm []byte) (*Keypair, error) {
+	sk, err := hkdfModR(ikm, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return keypairFromInt(sk)
+}
+
+// KeypairFromSecretHex restores a keypair from a hex encoded secret key.
+func KeypairFromSecretHex(secret string) (*Keypair, error) {
+	raw, err := hex.DecodeString(strings.TrimPrefix(secret, "0x"))
+	if err != nil || len(raw) != SecretKeyLength {
+		return nil, ErrInvalidSecretKey
+	}
+
+	return keypairFromInt(new(big.Int).SetBytes(raw))
+}
+
+// PublicKeyHex returns the compressed 48-byte public key as 0x-prefixed hex.
+func (k *Keypair) PublicKeyHex() string {
+	pub := k.Public.Serialize()
+	return "0x" + hex.EncodeToString(pub[:])
+}
+
+// SecretKeyHex returns the 32-byte big-endian secret key as 0x-prefixed hex.
+func (k *Keypair) SecretKeyHex() string {
+	sec := k.Secret.Serialize()
+	return "0x" + hex.EncodeToString(sec[:])
+}
+
+// Sign signs the message with the proof-of-possession ciphersuite used by Ethereum.
+func (k *Keypair) Sign(message []byte) [SignatureLength]byte {
+	return blsu.Sign(k.Secret, message).Serialize()
+}
+
+// ParsePublicKey decodes and validates a 0x-prefixed compressed public key.
+func ParsePublicKey(pubkey string) (*blsu.Pubkey, error) {
+	raw, err := hex.DecodeString(strings.TrimPrefix(pubkey, "0x"))
+	if err != nil || len(raw) != PublicKeyLength {
+		return nil, ErrInvalidPublicKey
+	}
+
+	var buf [PublicKeyLength]byte
+	copy(buf[:], raw)
+
+	var pub blsu.Pubkey
+	if err := pub.Deserialize(&buf); err != nil {
+		return nil, ErrInvalidPublicKey
+	}
+
+	return &pub, nil
+}
+
+func keypairFromInt(sk *big.Int) (*Keypair, error) {
+	if sk.Sign() == 0 || sk.Cmp(curveOrder) >= 0 {
+		return nil, ErrInvalidSecretKey
+	}
+
+	var buf [SecretKeyLength]byte
+	sk.FillBytes(buf[:])
+
+	var secret blsu.SecretKey
+	if err := secret.Deserialize(&buf); err != nil {
+		return nil, err
+	}
+
+	public, err := blsu.SkToPk(&secret)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Keypair{Secret: &secret, Public: public}, nil
+}
+
+// hkdfModR implements HKDF_mod_r as specified in EIP-2333.
+func hkdfModR(ikm []byte, keyInfo []byte) (*big.Int, error) {
+	const l = 48
+
+	salt := []byte(keyGenSalt)
+	ikmWithZero := append(append([]byte{}, ikm...), 0)
+	info := append(append([]byte{}, keyInfo...), 0, l)
+	sk := new(big.Int)
+
+	for sk.Sign() == 0 {
+		digest := sha256.Sum256(salt)
+		salt = digest[:]
+
+		okm := make([]byte, l)
+		if _, err := io.ReadFull(hkdf.New(sha256.New, ikmWithZero, salt, info), okm); err != nil {
+			return nil, err
+		}
+
+		sk.SetBytes(okm)
+		sk.Mod(sk, curveOrder)
+	}
+
+	return sk, nil
+}
@@ -0,0 +1,26 @@
+package keys
+
+import (
+	"testing"
+
+	blsu "github.com/protolambda/bls12-381-util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeypairRoundTrip(t *testing.T) {
+	keypair, err := GenerateKeypair()
+	assert.NoError(t, err)
+	assert.Len(t, keypair.PublicKeyHex(), 2+2*PublicKeyLength)
+
+	restored, err := KeypairFromSecretHex(keypair.SecretKeyHex())
+	assert.NoError(t, err)
+	assert.Equal(t, keypair.PublicKeyHex(), restored.PublicKeyHex())
+
+	pub, err := ParsePublicKey(keypair.PublicKeyHex())
+	assert.NoError(t, err)
+
+	signature := keypair.Sign([]byte("message"))
+	var sig blsu.Signature
+	assert.NoError(t, sig.Deserialize(&signature))
+	assert.True(t, blsu.Verify(pub, []byte("message"), &sig))
+}
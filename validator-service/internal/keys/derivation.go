@@ -0,0 +1,159 @@
+package keys
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/tyler-smith/go-bip39"
+	"golang.org/x/crypto/hkdf"
+)
+
+const (
+	mnemonicEntropyBits = 256
+	lamportChunks       = 255
+	lamportChunkLength  = 32
+)
+
+var (
+	ErrInvalidMnemonic = errors.New("invalid mnemonic")
+	ErrInvalidPath     = errors.New("invalid derivation path")
+)
+
+// GenerateMnemonic returns a new 24 word BIP-39 mnemonic.
+func GenerateMnemonic() (string, error) {
+	entropy, err := bip39.NewEntropy(mnemonicEntropyBits)
+	if err != nil {
+		return "", err
+	}
+
+	return bip39.NewMnemonic(entropy)
+}
+
+// SeedFromMnemonic converts a BIP-39 mnemonic into the seed used as the EIP-2333 tree root.
+func SeedFromMnemonic(mnemonic string, password string) ([]byte, error) {
+	seed, err := bip39.NewSeedWithErrorChecking(mnemonic, password)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidMnemonic, err)
+	}
+
+	return seed, nil
+}
+
+// SigningKeyPath returns the EIP-2334 signing key path of the validator with the given index.
+func SigningKeyPath(index uint) string {
+	return fmt.Sprintf("m/12381/3600/%d/0/0", index)
+}
+
+// WithdrawalKeyPath returns the EIP-2334 withdrawal key path of the validator with the given index.
+func WithdrawalKeyPath(index uint) string {
+	return fmt.Sprintf("m/12381/3600/%d/0", index)
+}
+
+// DeriveKeypair derives the keypair at the given EIP-2334 path from a seed.
+func DeriveKeypair(seed []byte, path string) (*Keypair, error) {
+	indices, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	sk, err := deriveMasterSK(seed)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, index := range indices {
+		sk, err = deriveChildSK(sk, index)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return keypairFromInt(sk)
+}
+
+func parsePath(path string) ([]uint32, error) {
+	parts := strings.Split(strings.TrimSpace(path), "/")
+	if len(parts) < 1 || parts[0] != "m" {
+		return nil, ErrInvalidPath
+	}
+
+	indices := make([]uint32, 0, len(parts)-1)
+	for _, part := range parts[1:] {
+		index, err := strconv.ParseUint(part, 10, 32)
+		if err != nil {
+			return nil, ErrInvalidPath
+		}
+		indices = append(indices, uint32(index))
+	}
+
+	return indices, nil
+}
+
+// deriveMasterSK implements derive_master_SK from EIP-2333.
+func deriveMasterSK(seed []byte) (*big.Int, error) {
+	if len(seed) < 32 {
+		return nil, errors.New("seed must be at least 32 bytes")
+	}
+
+	return hkdfModR(seed, nil)
+}
+
+// deriveChildSK implements derive_child_SK from EIP-2333.
+func deriveChildSK(parentSK *big.Int, index uint32) (*big.Int, error) {
+	compressedLamportPK, err := parentSKToLamportPK(parentSK, index)
+	if err != nil {
+		return nil, err
+	}
+
+	return hkdfModR(compressedLamportPK, nil)
+}
+
+func parentSKToLamportPK(parentSK *big.Int, index uint32) ([]byte, error) {
+	salt := []byte{byte(index >> 24), byte(index >> 16), byte(index >> 8), byte(index)}
+
+	ikm := make([]byte, 32)
+	parentSK.FillBytes(ikm)
+
+	notIKM := make([]byte, len(ikm))
+	for i, b := range ikm {
+		notIKM[i] = ^b
+	}
+
+	lamport0, err := ikmToLamportSK(ikm, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	lamport1, err := ikmToLamportSK(notIKM, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	lamportPK := make([]byte, 0, 2*lamportChunks*lamportChunkLength)
+	for _, chunk := range append(lamport0, lamport1...) {
+		digest := sha256.Sum256(chunk)
+		lamportPK = append(lamportPK, digest[:]...)
+	}
+
+	compressed := sha256.Sum256(lamportPK)
+	return compressed[:], nil
+}
+
+func ikmToLamportSK(ikm []byte, salt []byte) ([][]byte, error) {
+	okm := make([]byte, lamportChunks*lamportChunkLength)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, ikm, salt, nil), okm); err != nil {
+		return nil, err
+	}
+
+	chunks := make([][]byte, lamportChunks)
+	for i := range chunks {
+		chunks[i] = okm[i*lamportChunkLength : (i+1)*lamportChunkLength]
+	}
+
+	return chunks, nil
+}
@@ -0,0 +1,73 @@
+package keys
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test vectors from EIP-2333.
+var derivationVectors = []struct {
+	seed       string
+	masterSK   string
+	childIndex uint32
+	childSK    string
+}{
+	{
+		seed:       "c55257c360c07c72029aebc1b53c05ed0362ada38ead3e3e9efa3708e53495531f09a6987599d18264c1e1c92f2cf141630c7a3c4ab7c81b2f001698e7463b04",
+		masterSK:   "6083874454709270928345386274498605044986640685124978867557563392430687146096",
+		childIndex: 0,
+		childSK:    "20397789859736650942317412262472558107875392172444076792671091975210932703118",
+	},
+	{
+		seed:       "3141592653589793238462643383279502884197169399375105820974944592",
+		masterSK:   "29757020647961307431480504535336562678282505419141012933316116377660817309383",
+		childIndex: 3141592653,
+		childSK:    "25457201688850691947727629385191704516744796114925897962676248250929345014287",
+	},
+}
+
+func TestDeriveMasterAndChildSK(t *testing.T) {
+	for _, vector := range derivationVectors {
+		seed, _ := hex.DecodeString(vector.seed)
+
+		master, err := deriveMasterSK(seed)
+		assert.NoError(t, err)
+		assert.Equal(t, vector.masterSK, master.String())
+
+		child, err := deriveChildSK(master, vector.childIndex)
+		assert.NoError(t, err)
+		assert.Equal(t, vector.childSK, child.String())
+	}
+}
+
+func TestDeriveKeypairFromMnemonic(t *testing.T) {
+	mnemonic, err := GenerateMnemonic()
+	assert.NoError(t, err)
+
+	seed, err := SeedFromMnemonic(mnemonic, "")
+	assert.NoError(t, err)
+
+	first, err := DeriveKeypair(seed, SigningKeyPath(0))
+	assert.NoError(t, err)
+	again, err := DeriveKeypair(seed, SigningKeyPath(0))
+	assert.NoError(t, err)
+	second, err := DeriveKeypair(seed, SigningKeyPath(1))
+	assert.NoError(t, err)
+
+	assert.Equal(t, first.PublicKeyHex(), again.PublicKeyHex())
+	assert.NotEqual(t, first.PublicKeyHex(), second.PublicKeyHex())
+
+	_, err = SeedFromMnemonic("not a valid mnemonic", "")
+	assert.ErrorIs(t, err, ErrInvalidMnemonic)
+
+	_, err = DeriveKeypair(seed, "m/12381/x")
+	assert.ErrorIs(t, err, ErrInvalidPath)
+}
+
+func TestDeriveMasterSKRejectsShortSeed(t *testing.T) {
+	_, err := deriveMasterSK(big.NewInt(1).Bytes())
+	assert.Error(t, err)
+}
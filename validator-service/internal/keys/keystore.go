@@ -0,0 +1,288 @@
+package keys
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	KDFScrypt = "scrypt"
+	KDFPBKDF2 = "pbkdf2"
+
+	keystoreVersion = 4
+	keystoreDKLen   = 32
+
+	scryptN = 262144
+	scryptR = 8
+	scryptP = 1
+
+	pbkdf2C   = 262144
+	pbkdf2PRF = "hmac-sha256"
+
+	checksumFunction = "sha256"
+	cipherFunction   = "aes-128-ctr"
+)
+
+var (
+	ErrUnsupportedKDF   = errors.New("unsupported key derivation function")
+	ErrInvalidKeystore  = errors.New("invalid keystore")
+	ErrKeystoreChecksum = errors.New("keystore checksum mismatch")
+)
+
+// Keystore is an EIP-2335 BLS12-381 keystore.
+type Keystore struct {
+	Crypto      KeystoreCrypto `json:"crypto"`
+	Description string         `json:"description"`
+	Pubkey      string         `json:"pubkey"`
+	Path        string         `json:"path"`
+	UUID        string         `json:"uuid"`
+	Version     int            `json:"version"`
+}
+
+type KeystoreCrypto struct {
+	KDF      KeystoreModule `json:"kdf"`
+	Checksum KeystoreModule `json:"checksum"`
+	Cipher   KeystoreModule `json:"cipher"`
+}
+
+type KeystoreModule struct {
+	Function string                 `json:"function"`
+	Params   map[string]interface{} `json:"params"`
+	Message  string                 `json:"message"`
+}
+
+// EncryptKeystore encrypts the secret key of the keypair with the password into an EIP-2335 keystore.
+func EncryptKeystore(keypair *Keypair, password string, path string, kdf string) (*Keystore, error) {
+	salt, err := randomBytes(32)
+	if err != nil {
+		return nil, err
+	}
+
+	iv, err := randomBytes(aes.BlockSize)
+	if err != nil {
+		return nil, err
+	}
+
+	kdfModule := KeystoreModule{Function: kdf}
+	switch kdf {
+	case KDFScrypt:
+		kdfModule.Params = map[string]interface{}{
+			"dklen": keystoreDKLen,
+			"n":     scryptN,
+			"r":     scryptR,
+			"p":     scryptP,
+			"salt":  hex.EncodeToString(salt),
+		}
+	case KDFPBKDF2:
+		kdfModule.Params = map[string]interface{}{
+			"dklen": keystoreDKLen,
+			"c":     pbkdf2C,
+			"prf":   pbkdf2PRF,
+			"salt":  hex.EncodeToString(salt),
+		}
+	default:
+		return nil, ErrUnsupportedKDF
+	}
+
+	decryptionKey, err := deriveDecryptionKey(kdfModule, password)
+	if err != nil {
+		return nil, err
+	}
+
+	secret := keypair.Secret.Serialize()
+	cipherText, err := aes128CTR(decryptionKey[:16], iv, secret[:])
+	if err != nil {
+		return nil, err
+	}
+
+	pubkey := keypair.Public.Serialize()
+
+	return &Keystore{
+		Crypto: KeystoreCrypto{
+			KDF: kdfModule,
+			Checksum: KeystoreModule{
+				Function: checksumFunction,
+				Params:   map[string]interface{}{},
+				Message:  hex.EncodeToString(keystoreChecksum(decryptionKey, cipherText)),
+			},
+			Cipher: KeystoreModule{
+				Function: cipherFunction,
+				Params:   map[string]interface{}{"iv": hex.EncodeToString(iv)},
+				Message:  hex.EncodeToString(cipherText),
+			},
+		},
+		Pubkey:  hex.EncodeToString(pubkey[:]),
+		Path:    path,
+		UUID:    uuid.New().String(),
+		Version: keystoreVersion,
+	}, nil
+}
+
+// Decrypt recovers the keypair stored in the keystore.
+func (ks *Keystore) Decrypt(password string) (*Keypair, error) {
+	if ks.Version != keystoreVersion || ks.Crypto.Cipher.Function != cipherFunction || ks.Crypto.Checksum.Function != checksumFunction {
+		return nil, ErrInvalidKeystore
+	}
+
+	cipherText, err := hex.DecodeString(ks.Crypto.Cipher.Message)
+	if err != nil {
+		return nil, ErrInvalidKeystore
+	}
+
+	checksum, err := hex.DecodeString(ks.Crypto.Checksum.Message)
+	if err != nil {
+		return nil, ErrInvalidKeystore
+	}
+
+	iv, err := hexParam(ks.Crypto.Cipher.Params, "iv")
+	if err != nil {
+		return nil, err
+	}
+
+	decryptionKey, err := deriveDecryptionKey(ks.Crypto.KDF, password)
+	if err != nil {
+		return nil, err
+	}
+
+	if !bytes.Equal(keystoreChecksum(decryptionKey, cipherText), checksum) {
+		return nil, ErrKeystoreChecksum
+	}
+
+	secret, err := aes128CTR(decryptionKey[:16], iv, cipherText)
+	if err != nil {
+		return nil, err
+	}
+
+	return KeypairFromSecretHex(hex.EncodeToString(secret))
+}
+
+// FileName returns the file name used by the staking-deposit-cli for the keystore.
+func (ks *Keystore) FileName(timestamp int64) string {
+	return fmt.Sprintf("keystore-%s-%d.json", strings.ReplaceAll(ks.Path, "/", "_"), timestamp)
+}
+
+func deriveDecryptionKey(module KeystoreModule, password string) ([]byte, error) {
+	salt, err := hexParam(module.Params, "salt")
+	if err != nil {
+		return nil, err
+	}
+
+	dkLen, err := intParam(module.Params, "dklen")
+	if err != nil {
+		return nil, err
+	}
+
+	if dkLen < keystoreDKLen {
+		return nil, ErrInvalidKeystore
+	}
+
+	normalized := normalizePassword(password)
+
+	switch module.Function {
+	case KDFScrypt:
+		n, err := intParam(module.Params, "n")
+		if err != nil {
+			return nil, err
+		}
+		r, err := intParam(module.Params, "r")
+		if err != nil {
+			return nil, err
+		}
+		p, err := intParam(module.Params, "p")
+		if err != nil {
+			return nil, err
+		}
+
+		return scrypt.Key(normalized, salt, n, r, p, dkLen)
+	case KDFPBKDF2:
+		c, err := intParam(module.Params, "c")
+		if err != nil {
+			return nil, err
+		}
+		if prf, _ := module.Params["prf"].(string); prf != pbkdf2PRF {
+			return nil, ErrUnsupportedKDF
+		}
+
+		return pbkdf2.Key(normalized, salt, c, dkLen, sha256.New), nil
+	default:
+		return nil, ErrUnsupportedKDF
+	}
+}
+
+// normalizePassword applies the EIP-2335 password processing: NFKD normalization
+// followed by stripping the C0, C1 and Delete control codes.
+func normalizePassword(password string) []byte {
+	var sb strings.Builder
+	for _, r := range norm.NFKD.String(password) {
+		if r < 0x20 || (r >= 0x7f && r <= 0x9f) {
+			continue
+		}
+		sb.WriteRune(r)
+	}
+
+	return []byte(sb.String())
+}
+
+func keystoreChecksum(decryptionKey []byte, cipherText []byte) []byte {
+	digest := sha256.Sum256(append(append([]byte{}, decryptionKey[16:32]...), cipherText...))
+	return digest[:]
+}
+
+func aes128CTR(key []byte, iv []byte, input []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	output := make([]byte, len(input))
+	cipher.NewCTR(block, iv).XORKeyStream(output, input)
+
+	return output, nil
+}
+
+func hexParam(params map[string]interface{}, name string) ([]byte, error) {
+	value, ok := params[name].(string)
+	if !ok {
+		return nil, fmt.Errorf("%w: missing %s", ErrInvalidKeystore, name)
+	}
+
+	decoded, err := hex.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid %s", ErrInvalidKeystore, name)
+	}
+
+	return decoded, nil
+}
+
+func intParam(params map[string]interface{}, name string) (int, error) {
+	switch value := params[name].(type) {
+	case int:
+		return value, nil
+	case float64:
+		return int(value), nil
+	default:
+		return 0, fmt.Errorf("%w: missing %s", ErrInvalidKeystore, name)
+	}
+}
+
+func randomBytes(length int) ([]byte, error) {
+	buf := make([]byte, length)
+	if _, err := io.ReadFull(rand.Reader, buf); err != nil {
+		return nil, err
+	}
+
+	return buf, nil
+}
@@ -0,0 +1,50 @@
+package keys
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizePassword(t *testing.T) {
+	// Password test vector from EIP-2335.
+	normalized := normalizePassword("\U0001d531\U0001d522\U0001d530\U0001d531\U0001d52d\U0001d51e\U0001d530\U0001d530\U0001d534\U0001d52c\U0001d52f\U0001d521\U0001f511")
+	assert.Equal(t, "7465737470617373776f7264f09f9491", hex.EncodeToString(normalized))
+
+	assert.Equal(t, "password", string(normalizePassword("pass\x00word\x7f")))
+}
+
+func TestKeystoreRoundTrip(t *testing.T) {
+	keypair, err := GenerateKeypair()
+	assert.NoError(t, err)
+
+	for _, kdf := range []string{KDFScrypt, KDFPBKDF2} {
+		keystore, err := EncryptKeystore(keypair, "testpassword", SigningKeyPath(0), kdf)
+		assert.NoError(t, err)
+		assert.Equal(t, kdf, keystore.Crypto.KDF.Function)
+		assert.Equal(t, keypair.PublicKeyHex(), "0x"+keystore.Pubkey)
+
+		// decrypt a keystore that went through JSON like an imported file would
+		encoded, err := json.Marshal(keystore)
+		assert.NoError(t, err)
+		var decoded Keystore
+		assert.NoError(t, json.Unmarshal(encoded, &decoded))
+
+		restored, err := decoded.Decrypt("testpassword")
+		assert.NoError(t, err)
+		assert.Equal(t, keypair.SecretKeyHex(), restored.SecretKeyHex())
+
+		_, err = decoded.Decrypt("wrongpassword")
+		assert.ErrorIs(t, err, ErrKeystoreChecksum)
+	}
+
+	_, err = EncryptKeystore(keypair, "testpassword", SigningKeyPath(0), "argon2")
+	assert.ErrorIs(t, err, ErrUnsupportedKDF)
+}
+
+func TestKeystoreFileName(t *testing.T) {
+	keystore := Keystore{Path: SigningKeyPath(3)}
+	assert.Equal(t, "keystore-m_12381_3600_3_0_0-1700000000.json", keystore.FileName(1700000000))
+}
@@ -0,0 +1,86 @@
+package middlewares
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"log"
+	"net/http"
+	"strings"
+	"validator-service/internal/services"
+)
+
+const (
+	APIKeyHeader = "X-API-Key"
+	// TenantIDKey is the gin context key of the id of the authenticated tenant.
+	TenantIDKey = "tenant_id"
+	// RoleKey is the gin context key of the role of the authenticated API key.
+	RoleKey = "role"
+	// APIKeyPrefixKey is the gin context key of the prefix of the authenticated API key.
+	APIKeyPrefixKey = "api_key_prefix"
+)
+
+const (
+	ErrMissingAPIKey        = "Missing API key"
+	ErrInvalidAPIKey        = "Invalid API key"
+	ErrAuthenticatingAPIKey = "Failed to authenticate API key"
+	ErrInternalServer       = "Internal server error"
+)
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// APIKeyAuth authenticates requests with an API key sent as bearer token in the Authorization header
+// or in the X-API-Key header, and stores the tenant, role and prefix of the key in the context.
+func APIKeyAuth(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := apiKeyFromRequest(c)
+		if key == "" {
+			c.Header("WWW-Authenticate", "Bearer")
+			c.AbortWithStatusJSON(http.StatusUnauthorized, &errorResponse{Error: ErrMissingAPIKey})
+			return
+		}
+
+		apiKey, err := services.AuthenticateAPIKey(db, key)
+		if errors.Is(err, services.ErrInvalidAPIKey) {
+			log.Printf("%s, path: %s", ErrInvalidAPIKey, c.FullPath())
+			c.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, &errorResponse{Error: ErrInvalidAPIKey})
+			return
+		}
+		if err != nil {
+			log.Printf("%s, error: %v", ErrAuthenticatingAPIKey, err)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, &errorResponse{Error: ErrInternalServer})
+			return
+		}
+
+		c.Set(TenantIDKey, apiKey.TenantID)
+		c.Set(RoleKey, apiKey.Role)
+		c.Set(APIKeyPrefixKey, apiKey.Prefix)
+		c.Next()
+	}
+}
+
+// TenantID returns the id of the tenant authenticated by APIKeyAuth.
+func TenantID(c *gin.Context) uint {
+	return c.GetUint(TenantIDKey)
+}
+
+// APIKeyPrefix returns the prefix of the API key authenticated by APIKeyAuth.
+func APIKeyPrefix(c *gin.Context) string {
+	return c.GetString(APIKeyPrefixKey)
+}
+
+func apiKeyFromRequest(c *gin.Context) string {
+	if key := c.GetHeader(APIKeyHeader); key != "" {
+		return key
+	}
+
+	scheme, token, found := strings.Cut(c.GetHeader("Authorization"), " ")
+	if found && strings.EqualFold(scheme, "Bearer") {
+		return strings.TrimSpace(token)
+	}
+
+	return ""
+}
@@ -0,0 +1,154 @@
+package middlewares_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"validator-service/internal/middlewares"
+	"validator-service/internal/migrations"
+	"validator-service/internal/models"
+	"validator-service/internal/repository"
+	"validator-service/internal/services"
+)
+
+func setupTestDB(t *testing.T) *gorm.DB {
+	db, err := repository.OpenDatabase(repository.DriverSQLite, filepath.Join(t.TempDir(), "middlewares.db"))
+	assert.NoError(t, err)
+
+	_, err = migrations.Up(db)
+	assert.NoError(t, err)
+
+	return db
+}
+
+// authenticatedKey is the response of the test endpoint, the API key stored in the context by APIKeyAuth.
+type authenticatedKey struct {
+	TenantID uint        `json:"tenant_id"`
+	Role     models.Role `json:"role"`
+	Prefix   string      `json:"prefix"`
+}
+
+func setupAuthRouter(db *gorm.DB) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.GET("/test", middlewares.APIKeyAuth(db), func(c *gin.Context) {
+		c.JSON(http.StatusOK, &authenticatedKey{
+			TenantID: middlewares.TenantID(c),
+			Role:     middlewares.Role(c),
+			Prefix:   middlewares.APIKeyPrefix(c),
+		})
+	})
+
+	return r
+}
+
+func TestAPIKeyAuth(t *testing.T) {
+	db := setupTestDB(t)
+	r := setupAuthRouter(db)
+
+	tenant, adminKey, err := services.CreateTenant(db, "acme")
+	assert.NoError(t, err)
+	viewer, viewerKey, err := services.CreateAPIKey(db, tenant.ID, models.RoleViewer)
+	assert.NoError(t, err)
+	revoked, revokedKey, err := services.CreateAPIKey(db, tenant.ID, models.RoleOperator)
+	assert.NoError(t, err)
+	_, err = repository.RevokeAPIKey(db, revoked.Prefix)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name            string
+		headers         map[string]string
+		status          int
+		error           string
+		wwwAuthenticate string
+	}{
+		{
+			name:            "missing key",
+			status:          http.StatusUnauthorized,
+			error:           middlewares.ErrMissingAPIKey,
+			wwwAuthenticate: "Bearer",
+		},
+		{
+			name:            "unsupported scheme",
+			headers:         map[string]string{"Authorization": "Basic " + viewerKey},
+			status:          http.StatusUnauthorized,
+			error:           middlewares.ErrMissingAPIKey,
+			wwwAuthenticate: "Bearer",
+		},
+		{
+			name:            "invalid key",
+			headers:         map[string]string{middlewares.APIKeyHeader: services.APIKeyPrefix + "invalid"},
+			status:          http.StatusUnauthorized,
+			error:           middlewares.ErrInvalidAPIKey,
+			wwwAuthenticate: `Bearer error="invalid_token"`,
+		},
+		{
+			name:            "key without prefix",
+			headers:         map[string]string{"Authorization": "Bearer invalid"},
+			status:          http.StatusUnauthorized,
+			error:           middlewares.ErrInvalidAPIKey,
+			wwwAuthenticate: `Bearer error="invalid_token"`,
+		},
+		{
+			name:            "revoked key",
+			headers:         map[string]string{"Authorization": "Bearer " + revokedKey},
+			status:          http.StatusUnauthorized,
+			error:           middlewares.ErrInvalidAPIKey,
+			wwwAuthenticate: `Bearer error="invalid_token"`,
+		},
+		{
+			name:    "X-API-Key header",
+			headers: map[string]string{middlewares.APIKeyHeader: viewerKey},
+			status:  http.StatusOK,
+		},
+		{
+			name:    "bearer token",
+			headers: map[string]string{"Authorization": "Bearer " + viewerKey},
+			status:  http.StatusOK,
+		},
+		{
+			name:    "lowercase bearer scheme",
+			headers: map[string]string{"Authorization": "bearer " + viewerKey},
+			status:  http.StatusOK,
+		},
+		{
+			name:    "X-API-Key header before bearer token",
+			headers: map[string]string{middlewares.APIKeyHeader: viewerKey, "Authorization": "Bearer " + adminKey},
+			status:  http.StatusOK,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/test", nil)
+			for name, value := range test.headers {
+				req.Header.Set(name, value)
+			}
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			assert.Equal(t, test.status, w.Code)
+
+			if test.status != http.StatusOK {
+				var response map[string]string
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, test.error, response["error"])
+				assert.Equal(t, test.wwwAuthenticate, w.Header().Get("WWW-Authenticate"))
+				return
+			}
+
+			var response authenticatedKey
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tenant.ID, response.TenantID)
+			assert.Equal(t, models.RoleViewer, response.Role)
+			assert.Equal(t, viewer.Prefix, response.Prefix)
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package middlewares
+
+import (
+	"github.com/gin-gonic/gin"
+	"log"
+	"net/http"
+	"validator-service/internal/models"
+)
+
+const ErrForbidden = "The role of the API key does not allow this request"
+
+// RequireRole rejects requests whose API key, authenticated by APIKeyAuth, does not include the
+// permissions of role.
+func RequireRole(role models.Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !Role(c).Includes(role) {
+			log.Printf("%s, api_key: %s, role: %s, required: %s, path: %s", ErrForbidden, APIKeyPrefix(c), Role(c), role, c.FullPath())
+			c.AbortWithStatusJSON(http.StatusForbidden, &errorResponse{Error: ErrForbidden})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// Role returns the role of the API key authenticated by APIKeyAuth.
+func Role(c *gin.Context) models.Role {
+	value, _ := c.Get(RoleKey)
+	role, _ := value.(models.Role)
+	return role
+}
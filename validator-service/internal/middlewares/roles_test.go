@@ -0,0 +1,53 @@
+package middlewares_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"validator-service/internal/middlewares"
+	"validator-service/internal/models"
+)
+
+func TestRequireRole(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name     string
+		role     any
+		required models.Role
+		status   int
+	}{
+		{"viewer on viewer route", models.RoleViewer, models.RoleViewer, http.StatusOK},
+		{"viewer on operator route", models.RoleViewer, models.RoleOperator, http.StatusForbidden},
+		{"viewer on admin route", models.RoleViewer, models.RoleAdmin, http.StatusForbidden},
+		{"operator on viewer route", models.RoleOperator, models.RoleViewer, http.StatusOK},
+		{"operator on admin route", models.RoleOperator, models.RoleAdmin, http.StatusForbidden},
+		{"admin on admin route", models.RoleAdmin, models.RoleAdmin, http.StatusOK},
+		{"unknown role", models.Role("superuser"), models.RoleViewer, http.StatusForbidden},
+		{"role of another type", "admin", models.RoleViewer, http.StatusForbidden},
+		{"no role", nil, models.RoleViewer, http.StatusForbidden},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := gin.New()
+			r.GET("/test", func(c *gin.Context) {
+				if test.role != nil {
+					c.Set(middlewares.RoleKey, test.role)
+				}
+			}, middlewares.RequireRole(test.required), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/test", nil))
+			assert.Equal(t, test.status, w.Code)
+			if test.status == http.StatusForbidden {
+				assert.Contains(t, w.Body.String(), middlewares.ErrForbidden)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package migrations
+
+import "gorm.io/gorm"
+
+// Schema created by AutoMigrate before versioned migrations were introduced.
+// Databases that already have these tables are brought up to date instead of recreated.
+
+type validatorRequest0001 struct {
+	gorm.Model
+	RequestUUID       string
+	NumValidators     uint
+	FeeRecipient      string
+	WithdrawalAddress string
+	Mnemonic          string
+	Status            string
+	Keys              []validatorKey0001 `gorm:"foreignKey:ValidatorRequestID"`
+}
+
+func (validatorRequest0001) TableName() string {
+	return "validator_requests"
+}
+
+type validatorKey0001 struct {
+	gorm.Model
+	ValidatorRequestID    uint
+	Key                   string
+	SecretKey             string
+	DerivationIndex       uint
+	WithdrawalCredentials string
+	FeeRecipient          string
+}
+
+func (validatorKey0001) TableName() string {
+	return "validator_keys"
+}
+
+func init() {
+	register(&Migration{
+		Version: 1,
+		Name:    "initial",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&validatorRequest0001{}, &validatorKey0001{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&validatorKey0001{}, &validatorRequest0001{})
+		},
+	})
+}
@@ -0,0 +1,38 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type job0002 struct {
+	gorm.Model
+	Kind           string `gorm:"index:idx_jobs_kind_reference,priority:1"`
+	ReferenceID    uint   `gorm:"index:idx_jobs_kind_reference,priority:2"`
+	Status         string `gorm:"index:idx_jobs_status_run_at,priority:1"`
+	Attempts       uint
+	MaxAttempts    uint
+	RunAt          time.Time `gorm:"index:idx_jobs_status_run_at,priority:2"`
+	LeaseOwner     string
+	LeaseExpiresAt *time.Time
+	HeartbeatAt    *time.Time
+	LastError      string
+}
+
+func (job0002) TableName() string {
+	return "jobs"
+}
+
+func init() {
+	register(&Migration{
+		Version: 2,
+		Name:    "jobs",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&job0002{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&job0002{})
+		},
+	})
+}
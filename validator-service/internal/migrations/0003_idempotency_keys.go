@@ -0,0 +1,34 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type idempotencyKey0003 struct {
+	gorm.Model
+	Key                string `gorm:"uniqueIndex:idx_idempotency_keys_key"`
+	RequestHash        string
+	ValidatorRequestID uint
+	ResponseStatus     int
+	ResponseBody       string
+	ExpiresAt          time.Time `gorm:"index:idx_idempotency_keys_expires_at"`
+}
+
+func (idempotencyKey0003) TableName() string {
+	return "idempotency_keys"
+}
+
+func init() {
+	register(&Migration{
+		Version: 3,
+		Name:    "idempotency_keys",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&idempotencyKey0003{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&idempotencyKey0003{})
+		},
+	})
+}
@@ -0,0 +1,39 @@
+package migrations
+
+import "gorm.io/gorm"
+
+// Indexes for listing and searching validator requests. Fee recipients are matched case-insensitively,
+// so the index is on the lowercase address.
+var validatorRequestIndexes0004 = []struct {
+	name   string
+	create string
+}{
+	{"idx_validator_requests_request_uuid", "CREATE UNIQUE INDEX idx_validator_requests_request_uuid ON validator_requests (request_uuid)"},
+	{"idx_validator_requests_status", "CREATE INDEX idx_validator_requests_status ON validator_requests (status)"},
+	{"idx_validator_requests_fee_recipient", "CREATE INDEX idx_validator_requests_fee_recipient ON validator_requests (LOWER(fee_recipient))"},
+}
+
+func init() {
+	register(&Migration{
+		Version: 4,
+		Name:    "validator_request_indexes",
+		Up: func(tx *gorm.DB) error {
+			for _, index := range validatorRequestIndexes0004 {
+				if err := tx.Exec(index.create).Error; err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			for _, index := range validatorRequestIndexes0004 {
+				if err := tx.Exec("DROP INDEX IF EXISTS " + index.name).Error; err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+	})
+}
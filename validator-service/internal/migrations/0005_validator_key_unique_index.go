@@ -0,0 +1,16 @@
+package migrations
+
+import "gorm.io/gorm"
+
+func init() {
+	register(&Migration{
+		Version: 5,
+		Name:    "validator_key_unique_index",
+		Up: func(tx *gorm.DB) error {
+			return tx.Exec("CREATE UNIQUE INDEX idx_validator_keys_key ON validator_keys (key)").Error
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Exec("DROP INDEX IF EXISTS idx_validator_keys_key").Error
+		},
+	})
+}
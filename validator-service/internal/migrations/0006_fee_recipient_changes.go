@@ -0,0 +1,28 @@
+package migrations
+
+import "gorm.io/gorm"
+
+type feeRecipientChange0006 struct {
+	gorm.Model
+	ValidatorRequestID uint `gorm:"index:idx_fee_recipient_changes_validator_request_id"`
+	ValidatorKeyID     *uint
+	OldFeeRecipient    string
+	NewFeeRecipient    string
+}
+
+func (feeRecipientChange0006) TableName() string {
+	return "fee_recipient_changes"
+}
+
+func init() {
+	register(&Migration{
+		Version: 6,
+		Name:    "fee_recipient_changes",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&feeRecipientChange0006{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&feeRecipientChange0006{})
+		},
+	})
+}
@@ -0,0 +1,39 @@
+package migrations
+
+import "gorm.io/gorm"
+
+type signedBlock0007 struct {
+	gorm.Model
+	ValidatorKeyID uint   `gorm:"uniqueIndex:idx_signed_blocks_validator_key_id_slot"`
+	Slot           uint64 `gorm:"uniqueIndex:idx_signed_blocks_validator_key_id_slot"`
+	SigningRoot    string
+}
+
+func (signedBlock0007) TableName() string {
+	return "signed_blocks"
+}
+
+type signedAttestation0007 struct {
+	gorm.Model
+	ValidatorKeyID uint   `gorm:"uniqueIndex:idx_signed_attestations_validator_key_id_target_epoch;index:idx_signed_attestations_validator_key_id_source_epoch"`
+	SourceEpoch    uint64 `gorm:"index:idx_signed_attestations_validator_key_id_source_epoch"`
+	TargetEpoch    uint64 `gorm:"uniqueIndex:idx_signed_attestations_validator_key_id_target_epoch"`
+	SigningRoot    string
+}
+
+func (signedAttestation0007) TableName() string {
+	return "signed_attestations"
+}
+
+func init() {
+	register(&Migration{
+		Version: 7,
+		Name:    "slashing_protection",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&signedBlock0007{}, &signedAttestation0007{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&signedAttestation0007{}, &signedBlock0007{})
+		},
+	})
+}
@@ -0,0 +1,37 @@
+package migrations
+
+import "gorm.io/gorm"
+
+type validatorKey0008 struct {
+	WithdrawalPubkey    string
+	WithdrawalSecretKey string
+}
+
+func (validatorKey0008) TableName() string {
+	return "validator_keys"
+}
+
+var validatorKeyWithdrawalColumns0008 = []string{"WithdrawalPubkey", "WithdrawalSecretKey"}
+
+func init() {
+	register(&Migration{
+		Version: 8,
+		Name:    "validator_key_withdrawal_keys",
+		Up: func(tx *gorm.DB) error {
+			for _, column := range validatorKeyWithdrawalColumns0008 {
+				if err := tx.Migrator().AddColumn(&validatorKey0008{}, column); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			for _, column := range validatorKeyWithdrawalColumns0008 {
+				if err := tx.Migrator().DropColumn(&validatorKey0008{}, column); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	})
+}
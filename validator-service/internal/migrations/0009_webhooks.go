@@ -0,0 +1,45 @@
+package migrations
+
+import "gorm.io/gorm"
+
+type validatorRequest0009 struct {
+	CallbackURL string
+}
+
+func (validatorRequest0009) TableName() string {
+	return "validator_requests"
+}
+
+type webhookDelivery0009 struct {
+	gorm.Model
+	ValidatorRequestID uint `gorm:"index:idx_webhook_deliveries_validator_request_id"`
+	JobID              uint
+	Event              string
+	URL                string
+	Attempt            uint
+	StatusCode         int
+	Error              string
+}
+
+func (webhookDelivery0009) TableName() string {
+	return "webhook_deliveries"
+}
+
+func init() {
+	register(&Migration{
+		Version: 9,
+		Name:    "webhooks",
+		Up: func(tx *gorm.DB) error {
+			if err := tx.Migrator().AddColumn(&validatorRequest0009{}, "CallbackURL"); err != nil {
+				return err
+			}
+			return tx.AutoMigrate(&webhookDelivery0009{})
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := tx.Migrator().DropTable(&webhookDelivery0009{}); err != nil {
+				return err
+			}
+			return tx.Migrator().DropColumn(&validatorRequest0009{}, "CallbackURL")
+		},
+	})
+}
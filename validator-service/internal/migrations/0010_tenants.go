@@ -0,0 +1,97 @@
+package migrations
+
+import "gorm.io/gorm"
+
+type tenant0010 struct {
+	gorm.Model
+	Name string `gorm:"uniqueIndex:idx_tenants_name"`
+}
+
+func (tenant0010) TableName() string {
+	return "tenants"
+}
+
+type apiKey0010 struct {
+	gorm.Model
+	TenantID uint   `gorm:"index:idx_api_keys_tenant_id"`
+	Prefix   string `gorm:"uniqueIndex:idx_api_keys_prefix"`
+	KeyHash  string `gorm:"uniqueIndex:idx_api_keys_key_hash"`
+}
+
+func (apiKey0010) TableName() string {
+	return "api_keys"
+}
+
+type validatorRequest0010 struct {
+	TenantID uint `gorm:"not null;default:0"`
+}
+
+func (validatorRequest0010) TableName() string {
+	return "validator_requests"
+}
+
+type idempotencyKey0010 struct {
+	TenantID uint `gorm:"not null;default:0"`
+}
+
+func (idempotencyKey0010) TableName() string {
+	return "idempotency_keys"
+}
+
+// Requests stored before tenants existed belong to no tenant (tenant_id 0) until they are adopted by one.
+// Idempotency keys are unique per tenant instead of globally.
+var tenantIndexes0010 = []struct {
+	name   string
+	create string
+}{
+	{"idx_validator_requests_tenant_id", "CREATE INDEX idx_validator_requests_tenant_id ON validator_requests (tenant_id)"},
+	{"idx_idempotency_keys_tenant_id_key", "CREATE UNIQUE INDEX idx_idempotency_keys_tenant_id_key ON idempotency_keys (tenant_id, key)"},
+}
+
+func init() {
+	register(&Migration{
+		Version: 10,
+		Name:    "tenants",
+		Up: func(tx *gorm.DB) error {
+			if err := tx.AutoMigrate(&tenant0010{}, &apiKey0010{}); err != nil {
+				return err
+			}
+			if err := tx.Migrator().AddColumn(&validatorRequest0010{}, "TenantID"); err != nil {
+				return err
+			}
+			if err := tx.Migrator().AddColumn(&idempotencyKey0010{}, "TenantID"); err != nil {
+				return err
+			}
+			if err := tx.Migrator().DropIndex(&idempotencyKey0010{}, "idx_idempotency_keys_key"); err != nil {
+				return err
+			}
+
+			for _, index := range tenantIndexes0010 {
+				if err := tx.Exec(index.create).Error; err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			for _, index := range tenantIndexes0010 {
+				if err := tx.Exec("DROP INDEX IF EXISTS " + index.name).Error; err != nil {
+					return err
+				}
+			}
+
+			if err := tx.Exec("CREATE UNIQUE INDEX idx_idempotency_keys_key ON idempotency_keys (key)").Error; err != nil {
+				return err
+			}
+			if err := tx.Migrator().DropColumn(&idempotencyKey0010{}, "TenantID"); err != nil {
+				return err
+			}
+			if err := tx.Migrator().DropColumn(&validatorRequest0010{}, "TenantID"); err != nil {
+				return err
+			}
+
+			return tx.Migrator().DropTable(&apiKey0010{}, &tenant0010{})
+		},
+	})
+}
@@ -0,0 +1,26 @@
+package migrations
+
+import "gorm.io/gorm"
+
+// apiKey0011 adds the role of API keys. Keys created before roles existed had access to all
+// endpoints, so they become admin keys.
+type apiKey0011 struct {
+	Role string `gorm:"not null;default:admin"`
+}
+
+func (apiKey0011) TableName() string {
+	return "api_keys"
+}
+
+func init() {
+	register(&Migration{
+		Version: 11,
+		Name:    "api_key_roles",
+		Up: func(tx *gorm.DB) error {
+			return tx.Migrator().AddColumn(&apiKey0011{}, "Role")
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropColumn(&apiKey0011{}, "Role")
+		},
+	})
+}
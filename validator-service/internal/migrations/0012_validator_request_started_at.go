@@ -0,0 +1,39 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// validatorRequest0012 records when the request was last started, by its creation or by adding validators
+// to it. A cancellation discards the keys derived since then.
+type validatorRequest0012 struct {
+	StartedAt *time.Time
+}
+
+func (validatorRequest0012) TableName() string {
+	return "validator_requests"
+}
+
+func init() {
+	register(&Migration{
+		Version: 12,
+		Name:    "validator_request_started_at",
+		Up: func(tx *gorm.DB) error {
+			if err := tx.Migrator().AddColumn(&validatorRequest0012{}, "StartedAt"); err != nil {
+				return err
+			}
+
+			// requests were started when their last job was queued, requests from before the job queue when
+			// they were created
+			return tx.Exec(
+				"UPDATE validator_requests SET started_at = COALESCE((SELECT MAX(jobs.created_at) FROM jobs WHERE jobs.kind = ? AND jobs.reference_id = validator_requests.id), created_at)",
+				"validator_request",
+			).Error
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropColumn(&validatorRequest0012{}, "StartedAt")
+		},
+	})
+}
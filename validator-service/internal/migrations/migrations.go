@@ -0,0 +1,219 @@
+package migrations
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// advisoryLockKey serializes migrations of replicas starting at the same time on PostgreSQL.
+const advisoryLockKey = 7_400_812_001
+
+var ErrUnknownVersion = errors.New("applied migration is unknown to this binary")
+
+// Migration is a versioned, reversible schema change. Migrations must not use the models
+// package, they describe the tables as they looked at the time of the migration.
+type Migration struct {
+	Version uint
+	Name    string
+	Up      func(tx *gorm.DB) error
+	Down    func(tx *gorm.DB) error
+}
+
+// SchemaMigration is a row of the version table.
+type SchemaMigration struct {
+	Version   uint `gorm:"primaryKey;autoIncrement:false"`
+	Name      string
+	AppliedAt time.Time
+}
+
+// Status describes whether a migration has been applied.
+type Status struct {
+	Version   uint
+	Name      string
+	Applied   bool
+	AppliedAt *time.Time
+}
+
+var registry []*Migration
+
+func register(migration *Migration) {
+	registry = append(registry, migration)
+	sort.Slice(registry, func(i, j int) bool {
+		return registry[i].Version < registry[j].Version
+	})
+}
+
+// All returns the known migrations in version order.
+func All() []*Migration {
+	return registry
+}
+
+// Up applies all pending migrations in version order and returns the applied ones.
+func Up(db *gorm.DB) ([]*Migration, error) {
+	if err := ensureVersionTable(db); err != nil {
+		return nil, err
+	}
+
+	var applied []*Migration
+	for _, migration := range registry {
+		done, err := apply(db, migration)
+		if err != nil {
+			return applied, fmt.Errorf("migration %d_%s failed: %w", migration.Version, migration.Name, err)
+		}
+		if done {
+			applied = append(applied, migration)
+		}
+	}
+
+	return applied, nil
+}
+
+// Down rolls back the last steps applied migrations and returns the rolled back ones.
+func Down(db *gorm.DB, steps int) ([]*Migration, error) {
+	if err := ensureVersionTable(db); err != nil {
+		return nil, err
+	}
+
+	var rolledBack []*Migration
+	for i := 0; i < steps; i++ {
+		migration, err := rollbackLast(db)
+		if err != nil {
+			return rolledBack, err
+		}
+		if migration == nil {
+			break
+		}
+		rolledBack = append(rolledBack, migration)
+	}
+
+	return rolledBack, nil
+}
+
+// GetStatus lists all known migrations with their applied state.
+func GetStatus(db *gorm.DB) ([]Status, error) {
+	if err := ensureVersionTable(db); err != nil {
+		return nil, err
+	}
+
+	applied, err := appliedMigrations(db)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(registry))
+	for _, migration := range registry {
+		status := Status{Version: migration.Version, Name: migration.Name}
+		if row, ok := applied[migration.Version]; ok {
+			status.Applied = true
+			status.AppliedAt = &row.AppliedAt
+		}
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+func apply(db *gorm.DB, migration *Migration) (bool, error) {
+	done := false
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := lock(tx); err != nil {
+			return err
+		}
+
+		var count int64
+		if err := tx.Model(&SchemaMigration{}).Where("version = ?", migration.Version).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return nil
+		}
+
+		if err := migration.Up(tx); err != nil {
+			return err
+		}
+
+		done = true
+		return tx.Create(&SchemaMigration{
+			Version:   migration.Version,
+			Name:      migration.Name,
+			AppliedAt: time.Now().UTC(),
+		}).Error
+	})
+
+	return done, err
+}
+
+func rollbackLast(db *gorm.DB) (*Migration, error) {
+	var rolledBack *Migration
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := lock(tx); err != nil {
+			return err
+		}
+
+		var last SchemaMigration
+		result := tx.Order("version DESC").Limit(1).Find(&last)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+
+		migration := find(last.Version)
+		if migration == nil {
+			return fmt.Errorf("%w: %d_%s", ErrUnknownVersion, last.Version, last.Name)
+		}
+
+		if err := migration.Down(tx); err != nil {
+			return fmt.Errorf("rollback of migration %d_%s failed: %w", migration.Version, migration.Name, err)
+		}
+
+		rolledBack = migration
+		return tx.Delete(&SchemaMigration{}, "version = ?", last.Version).Error
+	})
+
+	return rolledBack, err
+}
+
+func appliedMigrations(db *gorm.DB) (map[uint]SchemaMigration, error) {
+	var rows []SchemaMigration
+	if err := db.Order("version").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	applied := make(map[uint]SchemaMigration, len(rows))
+	for _, row := range rows {
+		applied[row.Version] = row
+	}
+
+	return applied, nil
+}
+
+func ensureVersionTable(db *gorm.DB) error {
+	if db.Migrator().HasTable(&SchemaMigration{}) {
+		return nil
+	}
+
+	return db.Migrator().CreateTable(&SchemaMigration{})
+}
+
+func lock(tx *gorm.DB) error {
+	if tx.Dialector.Name() != "postgres" {
+		return nil
+	}
+
+	return tx.Exec("SELECT pg_advisory_xact_lock(?)", advisoryLockKey).Error
+}
+
+func find(version uint) *Migration {
+	for _, migration := range registry {
+		if migration.Version == version {
+			return migration
+		}
+	}
+
+	return nil
+}
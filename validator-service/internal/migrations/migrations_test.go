@@ -0,0 +1,106 @@
+package migrations_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"validator-service/internal/migrations"
+	"validator-service/internal/models"
+	_ "validator-service/internal/repository" // registers the encrypted serializer used by the models
+)
+
+func TestUpStatusDown(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+
+	applied, err := migrations.Up(db)
+	assert.NoError(t, err)
+	assert.Len(t, applied, len(migrations.All()))
+	assert.True(t, db.Migrator().HasTable("validator_requests"))
+	assert.True(t, db.Migrator().HasTable("validator_keys"))
+	assert.True(t, db.Migrator().HasIndex("validator_requests", "idx_validator_requests_fee_recipient"))
+	assert.True(t, db.Migrator().HasIndex("validator_keys", "idx_validator_keys_key"))
+	assert.True(t, db.Migrator().HasIndex("signed_attestations", "idx_signed_attestations_validator_key_id_target_epoch"))
+
+	applied, err = migrations.Up(db)
+	assert.NoError(t, err)
+	assert.Empty(t, applied)
+
+	statuses, err := migrations.GetStatus(db)
+	assert.NoError(t, err)
+	for _, status := range statuses {
+		assert.True(t, status.Applied)
+	}
+
+	rolledBack, err := migrations.Down(db, len(migrations.All())+1)
+	assert.NoError(t, err)
+	assert.Len(t, rolledBack, len(migrations.All()))
+	assert.False(t, db.Migrator().HasTable("validator_requests"))
+	assert.False(t, db.Migrator().HasTable("validator_keys"))
+
+	statuses, err = migrations.GetStatus(db)
+	assert.NoError(t, err)
+	for _, status := range statuses {
+		assert.False(t, status.Applied)
+	}
+}
+
+func TestMigrationsMatchModels(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+
+	_, err = migrations.Up(db)
+	assert.NoError(t, err)
+
+	for _, model := range []interface{}{
+		&models.ValidatorRequest{},
+		&models.ValidatorKey{},
+		&models.Job{},
+		&models.IdempotencyKey{},
+		&models.FeeRecipientChange{},
+		&models.SignedBlock{},
+		&models.SignedAttestation{},
+		&models.WebhookDelivery{},
+		&models.Tenant{},
+		&models.APIKey{},
+	} {
+		statement := &gorm.Statement{DB: db}
+		assert.NoError(t, statement.Parse(model))
+		assert.True(t, db.Migrator().HasTable(model), statement.Schema.Table)
+
+		for _, field := range statement.Schema.Fields {
+			if field.DBName == "" {
+				continue
+			}
+			assert.True(t, db.Migrator().HasColumn(model, field.DBName), "%s.%s", statement.Schema.Table, field.DBName)
+		}
+	}
+}
+
+func TestValidatorRequestStartedAtIsBackfilled(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+
+	_, err = migrations.Up(db)
+	assert.NoError(t, err)
+	_, err = migrations.Down(db, 1)
+	assert.NoError(t, err)
+
+	// a request from before the job queue and a request whose validators were added by a later job
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local)
+	queuedAt := createdAt.Add(time.Hour)
+	assert.NoError(t, db.Exec("INSERT INTO validator_requests (id, created_at, updated_at, status) VALUES (1, ?, ?, 'started'), (2, ?, ?, 'started')", createdAt, createdAt, createdAt, createdAt).Error)
+	assert.NoError(t, db.Exec("INSERT INTO jobs (created_at, updated_at, kind, reference_id, status) VALUES (?, ?, 'validator_request', 2, 'done'), (?, ?, 'validator_request', 2, 'pending')", createdAt, createdAt, queuedAt, queuedAt).Error)
+
+	_, err = migrations.Up(db)
+	assert.NoError(t, err)
+
+	var validatorRequests []models.ValidatorRequest
+	assert.NoError(t, db.Omit("mnemonic").Order("id").Find(&validatorRequests).Error)
+	assert.Len(t, validatorRequests, 2)
+	assert.True(t, createdAt.Equal(validatorRequests[0].StartedAt))
+	assert.True(t, queuedAt.Equal(validatorRequests[1].StartedAt))
+}
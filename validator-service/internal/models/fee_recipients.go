@@ -0,0 +1,13 @@
+package models
+
+import "gorm.io/gorm"
+
+// FeeRecipientChange records a change of the fee recipient of a request or of one of its keys.
+// ValidatorKeyID is nil when the default fee recipient of the request changed.
+type FeeRecipientChange struct {
+	gorm.Model
+	ValidatorRequestID uint   `json:"validator_request_id"`
+	ValidatorKeyID     *uint  `json:"validator_key_id"`
+	OldFeeRecipient    string `json:"old_fee_recipient"`
+	NewFeeRecipient    string `json:"new_fee_recipient"`
+}
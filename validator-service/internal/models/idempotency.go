@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// IdempotencyKey stores the response of a request made with an Idempotency-Key header,
+// so that retries of the request are answered with the original response.
+type IdempotencyKey struct {
+	gorm.Model
+	TenantID           uint      `json:"tenant_id"`
+	Key                string    `json:"key"`
+	RequestHash        string    `json:"request_hash"`
+	ValidatorRequestID uint      `json:"validator_request_id"`
+	ResponseStatus     int       `json:"response_status"`
+	ResponseBody       string    `json:"response_body"`
+	ExpiresAt          time.Time `json:"expires_at"`
+}
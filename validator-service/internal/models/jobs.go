@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type JobStatus string
+
+const (
+	JobPending   JobStatus = "pending"
+	JobRunning   JobStatus = "running"
+	JobDone      JobStatus = "done"
+	JobDead      JobStatus = "dead"
+	JobCancelled JobStatus = "cancelled"
+)
+
+type Job struct {
+	gorm.Model
+	Kind           string     `json:"kind"`
+	ReferenceID    uint       `json:"reference_id"`
+	Status         JobStatus  `json:"status"`
+	Attempts       uint       `json:"attempts"`
+	MaxAttempts    uint       `json:"max_attempts"`
+	RunAt          time.Time  `json:"run_at"`
+	LeaseOwner     string     `json:"lease_owner"`
+	LeaseExpiresAt *time.Time `json:"lease_expires_at"`
+	HeartbeatAt    *time.Time `json:"heartbeat_at"`
+	LastError      string     `json:"last_error"`
+}
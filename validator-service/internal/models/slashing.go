@@ -0,0 +1,22 @@
+package models
+
+import "gorm.io/gorm"
+
+// SignedBlock records a block proposal signed with a validator key for slashing protection.
+// SigningRoot is empty for imported blocks without signing root.
+type SignedBlock struct {
+	gorm.Model
+	ValidatorKeyID uint   `json:"validator_key_id"`
+	Slot           uint64 `json:"slot"`
+	SigningRoot    string `json:"signing_root"`
+}
+
+// SignedAttestation records an attestation signed with a validator key for slashing protection.
+// SigningRoot is empty for imported attestations without signing root.
+type SignedAttestation struct {
+	gorm.Model
+	ValidatorKeyID uint   `json:"validator_key_id"`
+	SourceEpoch    uint64 `json:"source_epoch"`
+	TargetEpoch    uint64 `json:"target_epoch"`
+	SigningRoot    string `json:"signing_root"`
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"slices"
+
+	"gorm.io/gorm"
+)
+
+// Role limits the endpoints an API key may call. Every role includes the permissions of the roles before it.
+type Role string
+
+const (
+	// RoleViewer reads requests, keys and their configuration, e.g. for dashboards.
+	RoleViewer Role = "viewer"
+	// RoleOperator also creates and changes requests and signs with their keys.
+	RoleOperator Role = "operator"
+	// RoleAdmin also exports secrets, imports slashing protection data and manages the API keys of the tenant.
+	RoleAdmin Role = "admin"
+)
+
+// Roles lists the roles from the least to the most privileged.
+var Roles = []Role{RoleViewer, RoleOperator, RoleAdmin}
+
+// Includes reports whether the role has the permissions of other. Unknown roles include no permissions
+// and are included by no role.
+func (r Role) Includes(other Role) bool {
+	index, otherIndex := slices.Index(Roles, r), slices.Index(Roles, other)
+	return index >= 0 && otherIndex >= 0 && index >= otherIndex
+}
+
+// Tenant owns validator requests. Tenants only see the requests and keys they own.
+type Tenant struct {
+	gorm.Model
+	Name string `json:"name"`
+}
+
+// APIKey authenticates the requests of a tenant. Only the SHA-256 hash of the key is stored,
+// the prefix tells keys apart, e.g. when revoking them. Revoked keys are soft deleted.
+type APIKey struct {
+	gorm.Model
+	TenantID uint   `json:"tenant_id"`
+	Prefix   string `json:"prefix"`
+	KeyHash  string `json:"-"`
+	Role     Role   `json:"role"`
+}
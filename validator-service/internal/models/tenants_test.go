@@ -0,0 +1,37 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"validator-service/internal/models"
+)
+
+func TestRoleIncludes(t *testing.T) {
+	tests := []struct {
+		role     models.Role
+		other    models.Role
+		includes bool
+	}{
+		{models.RoleViewer, models.RoleViewer, true},
+		{models.RoleViewer, models.RoleOperator, false},
+		{models.RoleViewer, models.RoleAdmin, false},
+		{models.RoleOperator, models.RoleViewer, true},
+		{models.RoleOperator, models.RoleOperator, true},
+		{models.RoleOperator, models.RoleAdmin, false},
+		{models.RoleAdmin, models.RoleViewer, true},
+		{models.RoleAdmin, models.RoleOperator, true},
+		{models.RoleAdmin, models.RoleAdmin, true},
+		// unknown roles have no permissions, and no known role has the permissions of an unknown one
+		{"", models.RoleViewer, false},
+		{"superuser", models.RoleViewer, false},
+		{"Admin", models.RoleViewer, false},
+		{models.RoleAdmin, "superuser", false},
+	}
+
+	for _, test := range tests {
+		t.Run(string(test.role)+"/"+string(test.other), func(t *testing.T) {
+			assert.Equal(t, test.includes, test.role.Includes(test.other))
+		})
+	}
+}
@@ -1,27 +1,48 @@
 package models
 
-import "gorm.io/gorm"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type RequestStatus string
 
 const (
 	RequestStarted    RequestStatus = "started"
+	RequestProcessing RequestStatus = "processing"
 	RequestSuccessful RequestStatus = "successful"
 	RequestFailed     RequestStatus = "failed"
+	RequestCancelled  RequestStatus = "cancelled"
 )
 
+// RequestsInProgress are the statuses of requests that are queued or being processed.
+var RequestsInProgress = []RequestStatus{RequestStarted, RequestProcessing}
+
 type ValidatorRequest struct {
 	gorm.Model
-	RequestUUID   string         `json:"request_uuid"`
-	NumValidators uint           `json:"num_validators"`
-	FeeRecipient  string         `json:"fee_recipient"`
-	Status        RequestStatus  `json:"status"`
-	Keys          []ValidatorKey `json:"keys" gorm:"foreignKey:ValidatorRequestID"`
+	TenantID          uint           `json:"tenant_id"`
+	RequestUUID       string         `json:"request_uuid"`
+	NumValidators     uint           `json:"num_validators"`
+	FeeRecipient      string         `json:"fee_recipient"`
+	WithdrawalAddress string         `json:"withdrawal_address"`
+	CallbackURL       string         `json:"callback_url"`
+	Mnemonic          string         `json:"-" gorm:"serializer:encrypted"`
+	Status            RequestStatus  `json:"status"`
+	StartedAt         time.Time      `json:"started_at"`
+	Keys              []ValidatorKey `json:"keys" gorm:"foreignKey:ValidatorRequestID"`
 }
 
 type ValidatorKey struct {
 	gorm.Model
-	ValidatorRequestID uint   `json:"validator_request_id"`
-	Key                string `json:"key"`
-	FeeRecipient       string `json:"fee_recipient"`
+	ValidatorRequestID    uint   `json:"validator_request_id"`
+	Key                   string `json:"key"`
+	SecretKey             string `json:"-" gorm:"serializer:encrypted"`
+	DerivationIndex       uint   `json:"derivation_index"`
+	WithdrawalCredentials string `json:"withdrawal_credentials"`
+	// WithdrawalPubkey and WithdrawalSecretKey are the BLS withdrawal key of keys with 0x00
+	// withdrawal credentials, empty for keys with an execution withdrawal address.
+	WithdrawalPubkey    string `json:"withdrawal_pubkey"`
+	WithdrawalSecretKey string `json:"-" gorm:"serializer:encrypted"`
+	FeeRecipient        string `json:"fee_recipient"`
 }
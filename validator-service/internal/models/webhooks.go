@@ -0,0 +1,21 @@
+package models
+
+import "gorm.io/gorm"
+
+const (
+	WebhookRequestSuccessful = "validator_request.successful"
+	WebhookRequestFailed     = "validator_request.failed"
+)
+
+// WebhookDelivery records one attempt to deliver a webhook to the callback URL of a request.
+// StatusCode is 0 when no response was received.
+type WebhookDelivery struct {
+	gorm.Model
+	ValidatorRequestID uint   `json:"validator_request_id"`
+	JobID              uint   `json:"job_id"`
+	Event              string `json:"event"`
+	URL                string `json:"url"`
+	Attempt            uint   `json:"attempt"`
+	StatusCode         int    `json:"status_code"`
+	Error              string `json:"error"`
+}
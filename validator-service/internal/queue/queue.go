@@ -0,0 +1,263 @@
+package queue
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"validator-service/internal/models"
+	"validator-service/internal/repository"
+)
+
+const (
+	DefaultWorkers       = 4
+	DefaultMaxAttempts   = 5
+	DefaultPollInterval  = time.Second
+	DefaultLeaseDuration = 30 * time.Second
+	DefaultBackoffBase   = 2 * time.Second
+	DefaultBackoffMax    = 5 * time.Minute
+)
+
+const (
+	ErrClaimingJob        = "Failed to claim job"
+	ErrUpdatingJob        = "Failed to update job"
+	ErrUnknownJobKind     = "Unknown job kind"
+	ErrExtendingJobLease  = "Failed to extend job lease"
+	ErrProcessingJob      = "Failed to process job"
+	ErrJobAttemptsReached = "Job reached max attempts"
+)
+
+var (
+	errLeaseLost    = errors.New("job lease lost")
+	errLeaseExpired = errors.New("job lease expired during its last attempt, the worker may have crashed")
+)
+
+// Handler processes jobs of one kind.
+type Handler interface {
+	// Process runs a job. The context is cancelled when the pool stops or the lease is lost.
+	Process(ctx context.Context, job *models.Job) error
+	// Dead is called once after a job exhausted all of its attempts.
+	Dead(job *models.Job, err error)
+}
+
+type Config struct {
+	Workers       int
+	PollInterval  time.Duration
+	LeaseDuration time.Duration
+	BackoffBase   time.Duration
+	BackoffMax    time.Duration
+}
+
+// Pool runs jobs persisted in the jobs table with a fixed number of workers. Running jobs hold a lease
+// that is extended by heartbeats, so jobs of a crashed process are picked up again once the lease expires.
+// A pool only runs jobs of the kinds registered with it, so that pools give job kinds their own workers.
+type Pool struct {
+	db       *gorm.DB
+	config   Config
+	owner    string
+	handlers map[string]Handler
+	kinds    []string
+	wakeup   chan struct{}
+	wg       sync.WaitGroup
+}
+
+func DefaultConfig() Config {
+	return Config{
+		Workers:       DefaultWorkers,
+		PollInterval:  DefaultPollInterval,
+		LeaseDuration: DefaultLeaseDuration,
+		BackoffBase:   DefaultBackoffBase,
+		BackoffMax:    DefaultBackoffMax,
+	}
+}
+
+func NewPool(db *gorm.DB, config Config) *Pool {
+	return &Pool{
+		db:       db,
+		config:   config,
+		owner:    newOwnerID(),
+		handlers: map[string]Handler{},
+		wakeup:   make(chan struct{}, 1),
+	}
+}
+
+// NewJob creates a pending job of the given kind for the entity with referenceID.
+func NewJob(kind string, referenceID uint, maxAttempts uint) *models.Job {
+	return &models.Job{
+		Kind:        kind,
+		ReferenceID: referenceID,
+		Status:      models.JobPending,
+		MaxAttempts: maxAttempts,
+		RunAt:       time.Now().UTC(),
+	}
+}
+
+// Register runs the jobs of kind with handler. It must be called before the pool is started.
+func (p *Pool) Register(kind string, handler Handler) {
+	if _, ok := p.handlers[kind]; !ok {
+		p.kinds = append(p.kinds, kind)
+	}
+	p.handlers[kind] = handler
+}
+
+// Notify wakes up an idle worker after a job was enqueued.
+func (p *Pool) Notify() {
+	select {
+	case p.wakeup <- struct{}{}:
+	default:
+	}
+}
+
+// Start runs the workers until ctx is cancelled.
+func (p *Pool) Start(ctx context.Context) {
+	for i := 0; i < p.config.Workers; i++ {
+		p.wg.Add(1)
+		go p.work(ctx)
+	}
+}
+
+// Wait blocks until all workers stopped.
+func (p *Pool) Wait() {
+	p.wg.Wait()
+}
+
+func (p *Pool) work(ctx context.Context) {
+	defer p.wg.Done()
+
+	for {
+		// jobs that took their worker down on every attempt are not run again
+		abandoned, err := repository.ClaimAbandonedJob(p.db, p.owner, p.config.LeaseDuration, p.kinds)
+		if err != nil {
+			log.Printf("%s: %v", ErrClaimingJob, err)
+		}
+
+		if abandoned != nil {
+			p.fail(abandoned, p.handlers[abandoned.Kind], errLeaseExpired)
+			continue
+		}
+
+		job, err := repository.ClaimJob(p.db, p.owner, p.config.LeaseDuration, p.kinds)
+		if err != nil {
+			log.Printf("%s: %v", ErrClaimingJob, err)
+		}
+
+		if job != nil {
+			p.run(ctx, job)
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-p.wakeup:
+		case <-time.After(p.config.PollInterval):
+		}
+	}
+}
+
+func (p *Pool) run(ctx context.Context, job *models.Job) {
+	handler, ok := p.handlers[job.Kind]
+	if !ok {
+		p.fail(job, nil, fmt.Errorf("%s: %s", ErrUnknownJobKind, job.Kind))
+		return
+	}
+
+	jobCtx, cancel := context.WithCancelCause(ctx)
+	heartbeatDone := make(chan struct{})
+	go p.heartbeat(jobCtx, cancel, job, heartbeatDone)
+
+	err := handler.Process(jobCtx, job)
+	cancel(nil)
+	<-heartbeatDone
+
+	switch {
+	case context.Cause(jobCtx) == errLeaseLost:
+		log.Printf("%s, job: %d, error: %v", ErrProcessingJob, job.ID, errLeaseLost)
+	case err == nil:
+		if err := repository.CompleteJob(p.db, job, p.owner); err != nil {
+			log.Printf("%s, job: %d, error: %v", ErrUpdatingJob, job.ID, err)
+		}
+	case ctx.Err() != nil:
+		// the pool is stopping, let the next worker retry without counting the attempt
+		if err := repository.ReleaseJob(p.db, job, p.owner); err != nil {
+			log.Printf("%s, job: %d, error: %v", ErrUpdatingJob, job.ID, err)
+		}
+	default:
+		p.fail(job, handler, err)
+	}
+}
+
+func (p *Pool) fail(job *models.Job, handler Handler, jobErr error) {
+	log.Printf("%s, job: %d, attempt: %d/%d, error: %v", ErrProcessingJob, job.ID, job.Attempts, job.MaxAttempts, jobErr)
+
+	if handler != nil && job.Attempts < job.MaxAttempts {
+		runAt := time.Now().Add(p.backoff(job.Attempts))
+		if err := repository.RetryJob(p.db, job, p.owner, runAt, jobErr); err != nil {
+			log.Printf("%s, job: %d, error: %v", ErrUpdatingJob, job.ID, err)
+		}
+		return
+	}
+
+	log.Printf("%s, job: %d", ErrJobAttemptsReached, job.ID)
+	if err := repository.MarkJobDead(p.db, job, p.owner, jobErr); err != nil {
+		log.Printf("%s, job: %d, error: %v", ErrUpdatingJob, job.ID, err)
+		return
+	}
+
+	if handler != nil {
+		handler.Dead(job, jobErr)
+	}
+}
+
+func (p *Pool) heartbeat(ctx context.Context, cancel context.CancelCauseFunc, job *models.Job, done chan<- struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(p.config.LeaseDuration / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			owned, err := repository.ExtendJobLease(p.db, job, p.owner, p.config.LeaseDuration)
+			if err != nil {
+				log.Printf("%s, job: %d, error: %v", ErrExtendingJobLease, job.ID, err)
+				continue
+			}
+			if !owned {
+				cancel(errLeaseLost)
+				return
+			}
+		}
+	}
+}
+
+// backoff returns the exponential delay before the next attempt.
+func (p *Pool) backoff(attempts uint) time.Duration {
+	delay := p.config.BackoffBase
+	for i := uint(1); i < attempts && delay < p.config.BackoffMax; i++ {
+		delay *= 2
+	}
+
+	return min(delay, p.config.BackoffMax)
+}
+
+func newOwnerID() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "worker"
+	}
+
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+
+	return hostname + "-" + hex.EncodeToString(suffix)
+}
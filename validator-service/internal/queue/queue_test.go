@@ -0,0 +1,205 @@
+package queue_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"validator-service/internal/migrations"
+	"validator-service/internal/models"
+	"validator-service/internal/queue"
+	"validator-service/internal/repository"
+)
+
+const testJobKind = "test"
+
+type testHandler struct {
+	mu        sync.Mutex
+	failures  int
+	processed int
+	dead      []uint
+}
+
+func (h *testHandler) Process(ctx context.Context, job *models.Job) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.processed++
+	if h.processed <= h.failures {
+		return errors.New("temporary failure")
+	}
+
+	return nil
+}
+
+func (h *testHandler) Dead(job *models.Job, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.dead = append(h.dead, job.ID)
+}
+
+// setupTestDB uses a database file because workers query the database from several connections.
+func setupTestDB(t *testing.T) *gorm.DB {
+	db, err := repository.OpenDatabase(repository.DriverSQLite, filepath.Join(t.TempDir(), "queue.db"))
+	assert.NoError(t, err)
+
+	_, err = migrations.Up(db)
+	assert.NoError(t, err)
+
+	return db
+}
+
+func startPool(t *testing.T, db *gorm.DB, handler queue.Handler) {
+	pool := queue.NewPool(db, queue.Config{
+		Workers:       2,
+		PollInterval:  10 * time.Millisecond,
+		LeaseDuration: time.Second,
+		BackoffBase:   time.Millisecond,
+		BackoffMax:    10 * time.Millisecond,
+	})
+	pool.Register(testJobKind, handler)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	pool.Start(ctx)
+
+	t.Cleanup(func() {
+		cancel()
+		pool.Wait()
+	})
+}
+
+func waitForStatus(t *testing.T, db *gorm.DB, id uint, status models.JobStatus) *models.Job {
+	var job models.Job
+	assert.Eventually(t, func() bool {
+		return db.First(&job, id).Error == nil && job.Status == status
+	}, 5*time.Second, 10*time.Millisecond)
+
+	return &job
+}
+
+func TestJobIsRetriedUntilItSucceeds(t *testing.T) {
+	db := setupTestDB(t)
+	handler := &testHandler{failures: 2}
+
+	job := queue.NewJob(testJobKind, 1, 5)
+	assert.NoError(t, repository.CreateJob(db, job))
+
+	startPool(t, db, handler)
+
+	done := waitForStatus(t, db, job.ID, models.JobDone)
+	assert.Equal(t, uint(3), done.Attempts)
+	assert.Empty(t, done.LastError)
+
+	handler.mu.Lock()
+	defer handler.mu.Unlock()
+	assert.Empty(t, handler.dead)
+}
+
+func TestJobIsDeadAfterMaxAttempts(t *testing.T) {
+	db := setupTestDB(t)
+	handler := &testHandler{failures: 10}
+
+	job := queue.NewJob(testJobKind, 1, 3)
+	assert.NoError(t, repository.CreateJob(db, job))
+
+	startPool(t, db, handler)
+
+	dead := waitForStatus(t, db, job.ID, models.JobDead)
+	assert.Equal(t, uint(3), dead.Attempts)
+	assert.Equal(t, "temporary failure", dead.LastError)
+
+	assert.Eventually(t, func() bool {
+		handler.mu.Lock()
+		defer handler.mu.Unlock()
+		return len(handler.dead) == 1
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestPoolOnlyRunsRegisteredKinds(t *testing.T) {
+	db := setupTestDB(t)
+	handler := &testHandler{}
+
+	other := queue.NewJob("other", 1, 5)
+	assert.NoError(t, repository.CreateJob(db, other))
+	job := queue.NewJob(testJobKind, 2, 5)
+	assert.NoError(t, repository.CreateJob(db, job))
+
+	startPool(t, db, handler)
+
+	waitForStatus(t, db, job.ID, models.JobDone)
+
+	// jobs of other kinds are left to the pools that registered them
+	var result models.Job
+	assert.NoError(t, db.First(&result, other.ID).Error)
+	assert.Equal(t, models.JobPending, result.Status)
+	assert.Zero(t, result.Attempts)
+}
+
+func TestExpiredLeaseIsReclaimed(t *testing.T) {
+	db := setupTestDB(t)
+	handler := &testHandler{}
+
+	// a job left running by a crashed worker
+	expired := time.Now().UTC().Add(-time.Minute)
+	job := queue.NewJob(testJobKind, 1, 5)
+	job.Status = models.JobRunning
+	job.Attempts = 1
+	job.LeaseOwner = "crashed-worker"
+	job.LeaseExpiresAt = &expired
+	assert.NoError(t, repository.CreateJob(db, job))
+
+	// a job with a valid lease of another worker must not be taken over
+	valid := time.Now().UTC().Add(time.Hour)
+	leased := queue.NewJob(testJobKind, 2, 5)
+	leased.Status = models.JobRunning
+	leased.Attempts = 1
+	leased.LeaseOwner = "live-worker"
+	leased.LeaseExpiresAt = &valid
+	assert.NoError(t, repository.CreateJob(db, leased))
+
+	startPool(t, db, handler)
+
+	done := waitForStatus(t, db, job.ID, models.JobDone)
+	assert.Equal(t, uint(2), done.Attempts)
+
+	var result models.Job
+	assert.NoError(t, db.First(&result, leased.ID).Error)
+	assert.Equal(t, models.JobRunning, result.Status)
+	assert.Equal(t, "live-worker", result.LeaseOwner)
+}
+
+func TestAbandonedJobIsDead(t *testing.T) {
+	db := setupTestDB(t)
+	handler := &testHandler{}
+
+	// a job whose last attempt crashed its worker, it must not run again
+	expired := time.Now().UTC().Add(-time.Minute)
+	job := queue.NewJob(testJobKind, 1, 3)
+	job.Status = models.JobRunning
+	job.Attempts = 3
+	job.LeaseOwner = "crashed-worker"
+	job.LeaseExpiresAt = &expired
+	assert.NoError(t, repository.CreateJob(db, job))
+
+	startPool(t, db, handler)
+
+	dead := waitForStatus(t, db, job.ID, models.JobDead)
+	assert.Equal(t, uint(3), dead.Attempts)
+	assert.Contains(t, dead.LastError, "lease expired")
+
+	assert.Eventually(t, func() bool {
+		handler.mu.Lock()
+		defer handler.mu.Unlock()
+		return len(handler.dead) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	handler.mu.Lock()
+	defer handler.mu.Unlock()
+	assert.Zero(t, handler.processed)
+}
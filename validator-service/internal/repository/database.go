@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+const (
+	DriverSQLite   = "sqlite"
+	DriverPostgres = "postgres"
+)
+
+// sqliteBusyTimeout makes concurrent writers of the job queue wait for the database lock instead of
+// failing with SQLITE_BUSY.
+const sqliteBusyTimeout = "_pragma=busy_timeout(5000)"
+
+var ErrUnsupportedDriver = errors.New("unsupported database driver, use sqlite or postgres")
+
+// OpenDatabase connects to the database of the given driver. The dsn is a file path
+// for SQLite and a connection string for PostgreSQL.
+func OpenDatabase(driver string, dsn string) (*gorm.DB, error) {
+	var dialector gorm.Dialector
+
+	switch driver {
+	case DriverSQLite:
+		dialector = sqlite.Open(sqliteDSN(dsn))
+	case DriverPostgres:
+		dialector = postgres.Open(dsn)
+	default:
+		return nil, ErrUnsupportedDriver
+	}
+
+	// translated errors let callers detect unique constraint violations with gorm.ErrDuplicatedKey
+	return gorm.Open(dialector, &gorm.Config{TranslateError: true})
+}
+
+func sqliteDSN(dsn string) string {
+	if strings.Contains(dsn, "busy_timeout") {
+		return dsn
+	}
+
+	if strings.Contains(dsn, "?") {
+		return dsn + "&" + sqliteBusyTimeout
+	}
+
+	return dsn + "?" + sqliteBusyTimeout
+}
@@ -0,0 +1,319 @@
+package repository
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"sync/atomic"
+
+	"gorm.io/gorm/schema"
+)
+
+// EncryptedSerializer is the name of the gorm serializer that stores string fields
+// envelope encrypted, e.g. `gorm:"serializer:encrypted"`.
+const EncryptedSerializer = "encrypted"
+
+const (
+	MasterKeyLength = 32
+
+	envelopePrefix = "enc:v1:"
+	dataKeyLength  = 32
+)
+
+var (
+	ErrKeyringNotConfigured = errors.New("encryption keyring is not configured")
+	ErrInvalidMasterKey     = errors.New("master key must be 32 bytes")
+	ErrUnknownMasterKey     = errors.New("secret is wrapped with an unknown master key")
+	ErrInvalidEnvelope      = errors.New("invalid encrypted value")
+)
+
+var activeKeyring atomic.Pointer[Keyring]
+
+func init() {
+	schema.RegisterSerializer(EncryptedSerializer, encryptedSerializer{})
+}
+
+// Keyring holds the primary master key that wraps new data keys and the
+// previous master keys that are only used to unwrap existing ones.
+type Keyring struct {
+	primary *masterKey
+	keys    map[string]*masterKey
+}
+
+type masterKey struct {
+	id   string
+	aead cipher.AEAD
+}
+
+// envelope is an encrypted value together with its data key wrapped by a master key.
+type envelope struct {
+	KeyID      string `json:"kid"`
+	DataKey    []byte `json:"dek"`
+	Ciphertext []byte `json:"ct"`
+}
+
+// NewKeyring creates a keyring that wraps data keys with primary and can still unwrap data keys wrapped with previous.
+func NewKeyring(primary []byte, previous ...[]byte) (*Keyring, error) {
+	primaryKey, err := newMasterKey(primary)
+	if err != nil {
+		return nil, err
+	}
+
+	keyring := &Keyring{
+		primary: primaryKey,
+		keys:    map[string]*masterKey{primaryKey.id: primaryKey},
+	}
+
+	for _, key := range previous {
+		previousKey, err := newMasterKey(key)
+		if err != nil {
+			return nil, err
+		}
+		keyring.keys[previousKey.id] = previousKey
+	}
+
+	return keyring, nil
+}
+
+// SetKeyring sets the keyring used to encrypt and decrypt fields with the encrypted serializer.
+func SetKeyring(keyring *Keyring) {
+	activeKeyring.Store(keyring)
+}
+
+// PrimaryKeyID returns the id of the master key that wraps new data keys.
+func (k *Keyring) PrimaryKeyID() string {
+	return k.primary.id
+}
+
+// Encrypt seals the plaintext with a fresh data key wrapped by the primary master key.
+func (k *Keyring) Encrypt(plaintext string) (string, error) {
+	dataKey := make([]byte, dataKeyLength)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return "", err
+	}
+
+	dataAEAD, err := newAEAD(dataKey)
+	if err != nil {
+		return "", err
+	}
+
+	ciphertext, err := seal(dataAEAD, []byte(plaintext))
+	if err != nil {
+		return "", err
+	}
+
+	wrappedDataKey, err := seal(k.primary.aead, dataKey)
+	if err != nil {
+		return "", err
+	}
+
+	return encodeEnvelope(&envelope{
+		KeyID:      k.primary.id,
+		DataKey:    wrappedDataKey,
+		Ciphertext: ciphertext,
+	})
+}
+
+// Decrypt opens a value produced by Encrypt.
+func (k *Keyring) Decrypt(value string) (string, error) {
+	env, err := decodeEnvelope(value)
+	if err != nil {
+		return "", err
+	}
+
+	dataKey, err := k.unwrap(env)
+	if err != nil {
+		return "", err
+	}
+
+	dataAEAD, err := newAEAD(dataKey)
+	if err != nil {
+		return "", err
+	}
+
+	plaintext, err := open(dataAEAD, env.Ciphertext)
+	if err != nil {
+		return "", err
+	}
+
+	return string(plaintext), nil
+}
+
+// Rewrap wraps the data key of an encrypted value with the primary master key without
+// touching the ciphertext. Plaintext values written before encryption was enabled are encrypted.
+func (k *Keyring) Rewrap(value string) (string, bool, error) {
+	if !IsEncrypted(value) {
+		encrypted, err := k.Encrypt(value)
+		return encrypted, true, err
+	}
+
+	env, err := decodeEnvelope(value)
+	if err != nil {
+		return "", false, err
+	}
+
+	if env.KeyID == k.primary.id {
+		return value, false, nil
+	}
+
+	dataKey, err := k.unwrap(env)
+	if err != nil {
+		return "", false, err
+	}
+
+	env.KeyID = k.primary.id
+	env.DataKey, err = seal(k.primary.aead, dataKey)
+	if err != nil {
+		return "", false, err
+	}
+
+	rewrapped, err := encodeEnvelope(env)
+	return rewrapped, true, err
+}
+
+// IsEncrypted reports whether the stored value is an encrypted envelope.
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, envelopePrefix)
+}
+
+func (k *Keyring) unwrap(env *envelope) ([]byte, error) {
+	key, ok := k.keys[env.KeyID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownMasterKey, env.KeyID)
+	}
+
+	return open(key.aead, env.DataKey)
+}
+
+func newMasterKey(key []byte) (*masterKey, error) {
+	if len(key) != MasterKeyLength {
+		return nil, ErrInvalidMasterKey
+	}
+
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	digest := sha256.Sum256(key)
+
+	return &masterKey{
+		id:   hex.EncodeToString(digest[:8]),
+		aead: aead,
+	}, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+func seal(aead cipher.AEAD, plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	return aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func open(aead cipher.AEAD, sealed []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, ErrInvalidEnvelope
+	}
+
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, nil)
+}
+
+func encodeEnvelope(env *envelope) (string, error) {
+	encoded, err := json.Marshal(env)
+	if err != nil {
+		return "", err
+	}
+
+	return envelopePrefix + base64.RawURLEncoding.EncodeToString(encoded), nil
+}
+
+func decodeEnvelope(value string) (*envelope, error) {
+	if !IsEncrypted(value) {
+		return nil, ErrInvalidEnvelope
+	}
+
+	encoded, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(value, envelopePrefix))
+	if err != nil {
+		return nil, ErrInvalidEnvelope
+	}
+
+	var env envelope
+	if err := json.Unmarshal(encoded, &env); err != nil {
+		return nil, ErrInvalidEnvelope
+	}
+
+	return &env, nil
+}
+
+// encryptedSerializer envelope encrypts string fields on write and decrypts them on read.
+// Values stored before encryption was enabled are read as plaintext until they are
+// encrypted by RotateMasterKey.
+type encryptedSerializer struct{}
+
+func (encryptedSerializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue interface{}) error {
+	var value string
+	switch v := dbValue.(type) {
+	case nil:
+	case []byte:
+		value = string(v)
+	case string:
+		value = v
+	default:
+		return fmt.Errorf("failed to scan encrypted value: %#v", dbValue)
+	}
+
+	if IsEncrypted(value) {
+		keyring := activeKeyring.Load()
+		if keyring == nil {
+			return ErrKeyringNotConfigured
+		}
+
+		plaintext, err := keyring.Decrypt(value)
+		if err != nil {
+			return fmt.Errorf("failed to decrypt %s: %w", field.DBName, err)
+		}
+		value = plaintext
+	}
+
+	return field.Set(ctx, dst, value)
+}
+
+func (encryptedSerializer) Value(ctx context.Context, field *schema.Field, dst reflect.Value, fieldValue interface{}) (interface{}, error) {
+	value, ok := fieldValue.(string)
+	if !ok {
+		return nil, fmt.Errorf("encrypted field %s must be a string", field.Name)
+	}
+
+	if value == "" {
+		return "", nil
+	}
+
+	keyring := activeKeyring.Load()
+	if keyring == nil {
+		return nil, ErrKeyringNotConfigured
+	}
+
+	return keyring.Encrypt(value)
+}
@@ -0,0 +1,95 @@
+package repository_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"validator-service/internal/models"
+	"validator-service/internal/repository"
+)
+
+func TestKeyringEncryptDecrypt(t *testing.T) {
+	keyring, err := repository.NewKeyring(bytes.Repeat([]byte{1}, repository.MasterKeyLength))
+	assert.NoError(t, err)
+
+	encrypted, err := keyring.Encrypt("secret")
+	assert.NoError(t, err)
+	assert.True(t, repository.IsEncrypted(encrypted))
+	assert.NotContains(t, encrypted, "secret")
+
+	decrypted, err := keyring.Decrypt(encrypted)
+	assert.NoError(t, err)
+	assert.Equal(t, "secret", decrypted)
+
+	otherKeyring, err := repository.NewKeyring(bytes.Repeat([]byte{2}, repository.MasterKeyLength))
+	assert.NoError(t, err)
+	_, err = otherKeyring.Decrypt(encrypted)
+	assert.ErrorIs(t, err, repository.ErrUnknownMasterKey)
+
+	_, err = repository.NewKeyring([]byte("short"))
+	assert.ErrorIs(t, err, repository.ErrInvalidMasterKey)
+}
+
+func TestSecretsAreEncryptedAtRest(t *testing.T) {
+	db := setupTestDB()
+	validator := models.ValidatorRequest{
+		RequestUUID: "encrypted_uuid",
+		Mnemonic:    "test mnemonic",
+		Keys:        []models.ValidatorKey{{Key: "key1", SecretKey: "0xsecret"}},
+	}
+	assert.NoError(t, repository.CreateValidatorRequest(db, &validator))
+
+	var rawMnemonic, rawSecretKey string
+	db.Table("validator_requests").Select("mnemonic").Where("id = ?", validator.ID).Scan(&rawMnemonic)
+	db.Table("validator_keys").Select("secret_key").Where("validator_request_id = ?", validator.ID).Scan(&rawSecretKey)
+	assert.True(t, repository.IsEncrypted(rawMnemonic))
+	assert.True(t, repository.IsEncrypted(rawSecretKey))
+
+	result, err := repository.GetValidatorRequestByUUID(db, validator.TenantID, validator.RequestUUID)
+	assert.NoError(t, err)
+	assert.Equal(t, "test mnemonic", result.Mnemonic)
+	assert.Equal(t, "0xsecret", result.Keys[0].SecretKey)
+}
+
+func TestRotateMasterKey(t *testing.T) {
+	db := setupTestDB()
+	validator := models.ValidatorRequest{
+		RequestUUID: "rotated_uuid",
+		Mnemonic:    "test mnemonic",
+		Keys:        []models.ValidatorKey{{Key: "key1", SecretKey: "0xsecret"}},
+	}
+	assert.NoError(t, repository.CreateValidatorRequest(db, &validator))
+
+	// a value written before encryption was enabled
+	db.Table("validator_keys").Create(map[string]interface{}{
+		"validator_request_id": validator.ID,
+		"key":                  "key2",
+		"derivation_index":     1,
+		"secret_key":           "0xlegacy",
+	})
+
+	oldMasterKey := make([]byte, repository.MasterKeyLength)
+	newMasterKey := bytes.Repeat([]byte{7}, repository.MasterKeyLength)
+	keyring, err := repository.NewKeyring(newMasterKey, oldMasterKey)
+	assert.NoError(t, err)
+
+	updated, err := repository.RotateMasterKey(db, keyring)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, updated)
+
+	updated, err = repository.RotateMasterKey(db, keyring)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, updated)
+
+	newOnlyKeyring, err := repository.NewKeyring(newMasterKey)
+	assert.NoError(t, err)
+	repository.SetKeyring(newOnlyKeyring)
+
+	result, err := repository.GetValidatorRequestByUUID(db, validator.TenantID, validator.RequestUUID)
+	assert.NoError(t, err)
+	assert.Equal(t, "test mnemonic", result.Mnemonic)
+	assert.Len(t, result.Keys, 2)
+	assert.Equal(t, "0xsecret", result.Keys[0].SecretKey)
+	assert.Equal(t, "0xlegacy", result.Keys[1].SecretKey)
+}
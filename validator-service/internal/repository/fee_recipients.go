@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+	"validator-service/internal/models"
+)
+
+const feeRecipientChangesBatchSize = 500
+
+func GetValidatorKeys(db *gorm.DB, validatorRequestID uint) ([]models.ValidatorKey, error) {
+	var validatorKeys []models.ValidatorKey
+	err := db.
+		Omit("secret_key", "withdrawal_secret_key").
+		Where("validator_request_id = ?", validatorRequestID).
+		Order("derivation_index").
+		Find(&validatorKeys).
+		Error
+
+	return validatorKeys, err
+}
+
+// GetAllValidatorKeyFeeRecipients returns the public key and fee recipient of every key of the tenant.
+func GetAllValidatorKeyFeeRecipients(db *gorm.DB, tenantID uint) ([]models.ValidatorKey, error) {
+	var validatorKeys []models.ValidatorKey
+	err := db.
+		Select("key", "fee_recipient").
+		Where("validator_request_id IN (?)", TenantValidatorRequestIDs(db, tenantID)).
+		Order("id").
+		Find(&validatorKeys).
+		Error
+
+	return validatorKeys, err
+}
+
+// UpdateRequestFeeRecipient sets the fee recipient of a request that is not in progress, so that keys
+// derived concurrently can not get the previous fee recipient. It fails with ErrStatusChanged otherwise.
+func UpdateRequestFeeRecipient(db *gorm.DB, validatorRequest *models.ValidatorRequest, feeRecipient string) error {
+	result := db.
+		Model(&models.ValidatorRequest{}).
+		Where("id = ? AND status NOT IN ?", validatorRequest.ID, models.RequestsInProgress).
+		Update("fee_recipient", feeRecipient)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected != 1 {
+		return ErrStatusChanged
+	}
+
+	return nil
+}
+
+// UpdateValidatorKeysFeeRecipient sets the fee recipient of all keys of the request.
+func UpdateValidatorKeysFeeRecipient(db *gorm.DB, validatorRequestID uint, feeRecipient string) error {
+	return db.
+		Model(&models.ValidatorKey{}).
+		Where("validator_request_id = ?", validatorRequestID).
+		Update("fee_recipient", feeRecipient).
+		Error
+}
+
+func UpdateValidatorKeyFeeRecipient(db *gorm.DB, validatorKey *models.ValidatorKey, feeRecipient string) error {
+	return db.
+		Model(&models.ValidatorKey{}).
+		Where("id = ?", validatorKey.ID).
+		Update("fee_recipient", feeRecipient).
+		Error
+}
+
+func CreateFeeRecipientChanges(db *gorm.DB, changes []models.FeeRecipientChange) error {
+	if len(changes) == 0 {
+		return nil
+	}
+
+	return db.CreateInBatches(&changes, feeRecipientChangesBatchSize).Error
+}
+
+// GetFeeRecipientChanges returns the fee recipient changes of the request, oldest first.
+func GetFeeRecipientChanges(db *gorm.DB, validatorRequestID uint) ([]models.FeeRecipientChange, error) {
+	var changes []models.FeeRecipientChange
+	err := db.
+		Where("validator_request_id = ?", validatorRequestID).
+		Order("id").
+		Find(&changes).
+		Error
+
+	return changes, err
+}
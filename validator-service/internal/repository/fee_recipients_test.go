@@ -0,0 +1,80 @@
+package repository_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"validator-service/internal/models"
+	"validator-service/internal/repository"
+)
+
+func TestUpdateRequestFeeRecipient(t *testing.T) {
+	db := setupTestDB()
+	validator := newBaseValidator()
+	validator.Status = models.RequestSuccessful
+	db.Create(&validator)
+
+	assert.NoError(t, repository.UpdateRequestFeeRecipient(db, &validator, "0x999"))
+	assert.NoError(t, repository.UpdateValidatorKeysFeeRecipient(db, validator.ID, "0x999"))
+
+	validatorKeys, err := repository.GetValidatorKeys(db, validator.ID)
+	assert.NoError(t, err)
+	assert.Len(t, validatorKeys, 5)
+	for _, validatorKey := range validatorKeys {
+		assert.Equal(t, "0x999", validatorKey.FeeRecipient)
+		assert.Empty(t, validatorKey.SecretKey)
+		assert.Empty(t, validatorKey.WithdrawalSecretKey)
+	}
+}
+
+func TestUpdateRequestFeeRecipientInProgress(t *testing.T) {
+	db := setupTestDB()
+	validator := newBaseValidator()
+	db.Create(&validator)
+
+	err := repository.UpdateRequestFeeRecipient(db, &validator, "0x999")
+	assert.ErrorIs(t, err, repository.ErrStatusChanged)
+
+	result, err := repository.GetValidatorRequestByID(db, validator.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, baseValidator.FeeRecipient, result.FeeRecipient)
+}
+
+func TestFeeRecipientChanges(t *testing.T) {
+	db := setupTestDB()
+	validator := newBaseValidator()
+	db.Create(&validator)
+
+	keyID := validator.Keys[1].ID
+	err := repository.CreateFeeRecipientChanges(db, []models.FeeRecipientChange{
+		{ValidatorRequestID: validator.ID, OldFeeRecipient: "0x123", NewFeeRecipient: "0x999"},
+		{ValidatorRequestID: validator.ID, ValidatorKeyID: &keyID, OldFeeRecipient: "0x124", NewFeeRecipient: "0x999"},
+	})
+	assert.NoError(t, err)
+
+	changes, err := repository.GetFeeRecipientChanges(db, validator.ID)
+	assert.NoError(t, err)
+	assert.Len(t, changes, 2)
+	assert.Nil(t, changes[0].ValidatorKeyID)
+	assert.Equal(t, keyID, *changes[1].ValidatorKeyID)
+	assert.Equal(t, "0x124", changes[1].OldFeeRecipient)
+}
+
+func TestGetAllValidatorKeyFeeRecipients(t *testing.T) {
+	db := setupTestDB()
+	validator := newBaseValidator()
+	db.Create(&validator)
+
+	other := models.ValidatorRequest{TenantID: testTenantID, RequestUUID: "other_uuid", Keys: []models.ValidatorKey{{Key: "key6", FeeRecipient: "0x128", SecretKey: "secret"}}}
+	db.Create(&other)
+
+	otherTenant := models.ValidatorRequest{TenantID: otherTenantID, RequestUUID: "other_tenant_uuid", Keys: []models.ValidatorKey{{Key: "key7", FeeRecipient: "0x129"}}}
+	db.Create(&otherTenant)
+
+	validatorKeys, err := repository.GetAllValidatorKeyFeeRecipients(db, testTenantID)
+	assert.NoError(t, err)
+	assert.Len(t, validatorKeys, 6)
+	assert.Equal(t, "key6", validatorKeys[5].Key)
+	assert.Equal(t, "0x128", validatorKeys[5].FeeRecipient)
+	assert.Empty(t, validatorKeys[5].SecretKey)
+}
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"validator-service/internal/models"
+)
+
+// GetIdempotencyKey returns the idempotency key stored by the tenant unless it expired before now.
+func GetIdempotencyKey(db *gorm.DB, tenantID uint, key string, now time.Time) (*models.IdempotencyKey, error) {
+	var idempotencyKey models.IdempotencyKey
+	err := db.
+		Where("tenant_id = ? AND key = ? AND expires_at > ?", tenantID, key, now.UTC()).
+		First(&idempotencyKey).
+		Error
+
+	return &idempotencyKey, err
+}
+
+// CreateIdempotencyKey stores the idempotency key, replacing an expired key of the tenant with the same value.
+// It fails with gorm.ErrDuplicatedKey when the key is in use, e.g. by a concurrent request.
+func CreateIdempotencyKey(db *gorm.DB, idempotencyKey *models.IdempotencyKey) error {
+	err := db.
+		Unscoped().
+		Where("tenant_id = ? AND key = ? AND expires_at <= ?", idempotencyKey.TenantID, idempotencyKey.Key, time.Now().UTC()).
+		Delete(&models.IdempotencyKey{}).
+		Error
+	if err != nil {
+		return err
+	}
+
+	return db.Create(idempotencyKey).Error
+}
+
+// DeleteExpiredIdempotencyKeys permanently deletes the keys that expired before now.
+func DeleteExpiredIdempotencyKeys(db *gorm.DB, now time.Time) (int64, error) {
+	result := db.
+		Unscoped().
+		Where("expires_at <= ?", now.UTC()).
+		Delete(&models.IdempotencyKey{})
+
+	return result.RowsAffected, result.Error
+}
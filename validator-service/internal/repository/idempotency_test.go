@@ -0,0 +1,84 @@
+package repository_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"validator-service/internal/models"
+	"validator-service/internal/repository"
+)
+
+func newIdempotencyKey(key string, expiresAt time.Time) *models.IdempotencyKey {
+	return &models.IdempotencyKey{
+		TenantID:       testTenantID,
+		Key:            key,
+		RequestHash:    "hash",
+		ResponseStatus: 200,
+		ResponseBody:   `{"request_id":"random_uuid"}`,
+		ExpiresAt:      expiresAt.UTC(),
+	}
+}
+
+func TestCreateIdempotencyKey(t *testing.T) {
+	db := setupTestDB()
+
+	assert.NoError(t, repository.CreateIdempotencyKey(db, newIdempotencyKey("key", time.Now().Add(time.Hour))))
+
+	result, err := repository.GetIdempotencyKey(db, testTenantID, "key", time.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, "hash", result.RequestHash)
+	assert.Equal(t, `{"request_id":"random_uuid"}`, result.ResponseBody)
+
+	// a second request with the same key loses on the unique constraint
+	err = repository.CreateIdempotencyKey(db, newIdempotencyKey("key", time.Now().Add(time.Hour)))
+	assert.ErrorIs(t, err, gorm.ErrDuplicatedKey)
+}
+
+func TestIdempotencyKeysAreScopedToTenant(t *testing.T) {
+	db := setupTestDB()
+
+	assert.NoError(t, repository.CreateIdempotencyKey(db, newIdempotencyKey("key", time.Now().Add(time.Hour))))
+
+	_, err := repository.GetIdempotencyKey(db, otherTenantID, "key", time.Now())
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	// another tenant can use the same key
+	otherKey := newIdempotencyKey("key", time.Now().Add(time.Hour))
+	otherKey.TenantID = otherTenantID
+	otherKey.RequestHash = "other hash"
+	assert.NoError(t, repository.CreateIdempotencyKey(db, otherKey))
+
+	result, err := repository.GetIdempotencyKey(db, otherTenantID, "key", time.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, "other hash", result.RequestHash)
+}
+
+func TestExpiredIdempotencyKeyIsReplaced(t *testing.T) {
+	db := setupTestDB()
+
+	assert.NoError(t, repository.CreateIdempotencyKey(db, newIdempotencyKey("key", time.Now().Add(-time.Minute))))
+
+	_, err := repository.GetIdempotencyKey(db, testTenantID, "key", time.Now())
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	assert.NoError(t, repository.CreateIdempotencyKey(db, newIdempotencyKey("key", time.Now().Add(time.Hour))))
+
+	_, err = repository.GetIdempotencyKey(db, testTenantID, "key", time.Now())
+	assert.NoError(t, err)
+}
+
+func TestDeleteExpiredIdempotencyKeys(t *testing.T) {
+	db := setupTestDB()
+
+	assert.NoError(t, repository.CreateIdempotencyKey(db, newIdempotencyKey("expired", time.Now().Add(-time.Minute))))
+	assert.NoError(t, repository.CreateIdempotencyKey(db, newIdempotencyKey("valid", time.Now().Add(time.Hour))))
+
+	deleted, err := repository.DeleteExpiredIdempotencyKeys(db, time.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+
+	_, err = repository.GetIdempotencyKey(db, testTenantID, "valid", time.Now())
+	assert.NoError(t, err)
+}
@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"validator-service/internal/models"
+)
+
+const claimCandidates = 10
+
+func CreateJob(db *gorm.DB, job *models.Job) error {
+	return db.Create(job).Error
+}
+
+// ClaimJob leases the next runnable job of one of kinds to owner and counts the attempt. Jobs are runnable
+// when they are pending and due, or when the lease of their previous owner expired and they have attempts
+// left. Returns nil when there is nothing to run.
+func ClaimJob(db *gorm.DB, owner string, lease time.Duration, kinds []string) (*models.Job, error) {
+	return claimJob(db, owner, lease, kinds, runnableJobs, gorm.Expr("attempts + 1"))
+}
+
+// ClaimAbandonedJob leases a job of one of kinds to owner whose lease expired during its last attempt, e.g.
+// because the attempt crashed the worker, so that owner can mark it dead. The attempt is not counted again.
+// Returns nil when there is no such job.
+func ClaimAbandonedJob(db *gorm.DB, owner string, lease time.Duration, kinds []string) (*models.Job, error) {
+	return claimJob(db, owner, lease, kinds, abandonedJobs, gorm.Expr("attempts"))
+}
+
+func claimJob(db *gorm.DB, owner string, lease time.Duration, kinds []string, claimable func(*gorm.DB, time.Time) *gorm.DB, attempts clause.Expr) (*models.Job, error) {
+	now := time.Now().UTC()
+
+	var candidates []models.Job
+	err := claimable(db.Model(&models.Job{}), now).
+		Where("kind IN ?", kinds).
+		Order("run_at").
+		Limit(claimCandidates).
+		Find(&candidates).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, candidate := range candidates {
+		leaseExpiresAt := now.Add(lease)
+
+		// the claimable condition is checked again so only one owner wins the job
+		result := claimable(db.Model(&models.Job{}).Where("id = ?", candidate.ID), now).
+			Updates(map[string]interface{}{
+				"status":           models.JobRunning,
+				"lease_owner":      owner,
+				"lease_expires_at": leaseExpiresAt,
+				"heartbeat_at":     now,
+				"attempts":         attempts,
+			})
+		if result.Error != nil {
+			return nil, result.Error
+		}
+
+		if result.RowsAffected == 1 {
+			var job models.Job
+			if err := db.First(&job, candidate.ID).Error; err != nil {
+				return nil, err
+			}
+
+			return &job, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// CountActiveJobs returns the number of pending and running jobs of kind.
+func CountActiveJobs(db *gorm.DB, kind string) (int64, error) {
+	var count int64
+	err := db.
+		Model(&models.Job{}).
+		Where("kind = ? AND status IN ?", kind, []models.JobStatus{models.JobPending, models.JobRunning}).
+		Count(&count).
+		Error
+
+	return count, err
+}
+
+// GetActiveJob returns the pending or running job of kind for the entity with referenceID.
+func GetActiveJob(db *gorm.DB, kind string, referenceID uint) (*models.Job, error) {
+	var job models.Job
+	err := db.
+		Where("kind = ? AND reference_id = ?", kind, referenceID).
+		Where("status IN ?", []models.JobStatus{models.JobPending, models.JobRunning}).
+		Order("id DESC").
+		First(&job).
+		Error
+
+	return &job, err
+}
+
+// CancelJob stops a pending or running job. The owner of a running job notices on its next heartbeat
+// that it lost the lease.
+func CancelJob(db *gorm.DB, job *models.Job) error {
+	return db.
+		Model(&models.Job{}).
+		Where("id = ? AND status IN ?", job.ID, []models.JobStatus{models.JobPending, models.JobRunning}).
+		Updates(map[string]interface{}{
+			"status":           models.JobCancelled,
+			"lease_owner":      "",
+			"lease_expires_at": nil,
+		}).
+		Error
+}
+
+// ExtendJobLease records a heartbeat of the owner. It returns false when the owner lost the lease.
+func ExtendJobLease(db *gorm.DB, job *models.Job, owner string, lease time.Duration) (bool, error) {
+	now := time.Now().UTC()
+
+	result := ownedJob(db, job, owner).
+		Updates(map[string]interface{}{
+			"lease_expires_at": now.Add(lease),
+			"heartbeat_at":     now,
+		})
+
+	return result.RowsAffected == 1, result.Error
+}
+
+func CompleteJob(db *gorm.DB, job *models.Job, owner string) error {
+	return ownedJob(db, job, owner).
+		Updates(map[string]interface{}{
+			"status":           models.JobDone,
+			"lease_owner":      "",
+			"lease_expires_at": nil,
+			"last_error":       "",
+		}).
+		Error
+}
+
+// RetryJob returns a failed job to the queue to run again at runAt.
+func RetryJob(db *gorm.DB, job *models.Job, owner string, runAt time.Time, jobErr error) error {
+	return ownedJob(db, job, owner).
+		Updates(map[string]interface{}{
+			"status":           models.JobPending,
+			"run_at":           runAt.UTC(),
+			"lease_owner":      "",
+			"lease_expires_at": nil,
+			"last_error":       jobErr.Error(),
+		}).
+		Error
+}
+
+// ReleaseJob returns an interrupted job to the queue without counting the attempt.
+func ReleaseJob(db *gorm.DB, job *models.Job, owner string) error {
+	return ownedJob(db, job, owner).
+		Updates(map[string]interface{}{
+			"status":           models.JobPending,
+			"run_at":           time.Now().UTC(),
+			"lease_owner":      "",
+			"lease_expires_at": nil,
+			"attempts":         gorm.Expr("attempts - 1"),
+		}).
+		Error
+}
+
+func MarkJobDead(db *gorm.DB, job *models.Job, owner string, jobErr error) error {
+	return ownedJob(db, job, owner).
+		Updates(map[string]interface{}{
+			"status":           models.JobDead,
+			"lease_owner":      "",
+			"lease_expires_at": nil,
+			"last_error":       jobErr.Error(),
+		}).
+		Error
+}
+
+func runnableJobs(db *gorm.DB, now time.Time) *gorm.DB {
+	return db.Where(
+		"(status = ? AND run_at <= ?) OR (status = ? AND lease_expires_at < ? AND attempts < max_attempts)",
+		models.JobPending, now, models.JobRunning, now,
+	)
+}
+
+func abandonedJobs(db *gorm.DB, now time.Time) *gorm.DB {
+	return db.Where(
+		"status = ? AND lease_expires_at < ? AND attempts >= max_attempts",
+		models.JobRunning, now,
+	)
+}
+
+func ownedJob(db *gorm.DB, job *models.Job, owner string) *gorm.DB {
+	return db.
+		Model(&models.Job{}).
+		Where("id = ? AND lease_owner = ? AND status = ?", job.ID, owner, models.JobRunning)
+}
@@ -0,0 +1,99 @@
+package repository_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"validator-service/internal/models"
+	"validator-service/internal/repository"
+)
+
+func TestCountActiveJobs(t *testing.T) {
+	db := setupTestDB()
+
+	for _, status := range []models.JobStatus{models.JobPending, models.JobRunning, models.JobDone, models.JobDead} {
+		assert.NoError(t, repository.CreateJob(db, &models.Job{Kind: "test", Status: status}))
+	}
+	assert.NoError(t, repository.CreateJob(db, &models.Job{Kind: "other", Status: models.JobPending}))
+
+	count, err := repository.CountActiveJobs(db, "test")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+}
+
+func TestClaimJobOfKinds(t *testing.T) {
+	db := setupTestDB()
+
+	other := &models.Job{Kind: "other", Status: models.JobPending, RunAt: time.Now().UTC().Add(-time.Minute)}
+	assert.NoError(t, repository.CreateJob(db, other))
+	job := &models.Job{Kind: "test", Status: models.JobPending, RunAt: time.Now().UTC()}
+	assert.NoError(t, repository.CreateJob(db, job))
+
+	claimed, err := repository.ClaimJob(db, "owner", time.Minute, []string{"test"})
+	assert.NoError(t, err)
+	assert.Equal(t, job.ID, claimed.ID)
+	assert.Equal(t, models.JobRunning, claimed.Status)
+
+	claimed, err = repository.ClaimJob(db, "owner", time.Minute, []string{"test"})
+	assert.NoError(t, err)
+	assert.Nil(t, claimed)
+}
+
+// newExpiredJob stores a job left running by a crashed worker after the attempts.
+func newExpiredJob(t *testing.T, db *gorm.DB, attempts uint, maxAttempts uint) *models.Job {
+	expired := time.Now().UTC().Add(-time.Minute)
+	job := &models.Job{
+		Kind:           "test",
+		Status:         models.JobRunning,
+		Attempts:       attempts,
+		MaxAttempts:    maxAttempts,
+		RunAt:          expired,
+		LeaseOwner:     "crashed-worker",
+		LeaseExpiresAt: &expired,
+	}
+	assert.NoError(t, repository.CreateJob(db, job))
+
+	return job
+}
+
+func TestClaimJobOnlyReclaimsExpiredJobsWithAttemptsLeft(t *testing.T) {
+	db := setupTestDB()
+	exhausted := newExpiredJob(t, db, 3, 3)
+	job := newExpiredJob(t, db, 2, 3)
+
+	claimed, err := repository.ClaimJob(db, "owner", time.Minute, []string{"test"})
+	assert.NoError(t, err)
+	assert.Equal(t, job.ID, claimed.ID)
+	assert.Equal(t, uint(3), claimed.Attempts)
+
+	claimed, err = repository.ClaimJob(db, "owner", time.Minute, []string{"test"})
+	assert.NoError(t, err)
+	assert.Nil(t, claimed)
+
+	var result models.Job
+	assert.NoError(t, db.First(&result, exhausted.ID).Error)
+	assert.Equal(t, uint(3), result.Attempts)
+	assert.Equal(t, "crashed-worker", result.LeaseOwner)
+}
+
+func TestClaimAbandonedJob(t *testing.T) {
+	db := setupTestDB()
+	newExpiredJob(t, db, 2, 3)
+	exhausted := newExpiredJob(t, db, 3, 3)
+
+	claimed, err := repository.ClaimAbandonedJob(db, "owner", time.Minute, []string{"test"})
+	assert.NoError(t, err)
+	assert.Equal(t, exhausted.ID, claimed.ID)
+	assert.Equal(t, "owner", claimed.LeaseOwner)
+	// the attempt that crashed the worker was already counted
+	assert.Equal(t, uint(3), claimed.Attempts)
+
+	assert.NoError(t, repository.MarkJobDead(db, claimed, "owner", errors.New("lease expired")))
+
+	claimed, err = repository.ClaimAbandonedJob(db, "owner", time.Minute, []string{"test"})
+	assert.NoError(t, err)
+	assert.Nil(t, claimed)
+}
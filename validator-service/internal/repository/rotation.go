@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"database/sql"
+	"strings"
+
+	"gorm.io/gorm"
+	"validator-service/internal/models"
+)
+
+const rotationBatchSize = 500
+
+// encryptedModels lists the models that have fields stored with the encrypted serializer.
+var encryptedModels = []interface{}{
+	&models.ValidatorRequest{},
+	&models.ValidatorKey{},
+}
+
+// RotateMasterKey rewraps the data keys of all encrypted fields with the primary master key of the
+// keyring and encrypts values written before encryption was enabled. It returns the number of updated values.
+func RotateMasterKey(db *gorm.DB, keyring *Keyring) (int, error) {
+	updated := 0
+
+	for _, model := range encryptedModels {
+		statement := &gorm.Statement{DB: db}
+		if err := statement.Parse(model); err != nil {
+			return updated, err
+		}
+
+		for _, field := range statement.Schema.Fields {
+			if strings.ToLower(field.TagSettings["SERIALIZER"]) != EncryptedSerializer {
+				continue
+			}
+
+			count, err := rotateColumn(db, keyring, statement.Schema.Table, field.DBName)
+			updated += count
+			if err != nil {
+				return updated, err
+			}
+		}
+	}
+
+	return updated, nil
+}
+
+func rotateColumn(db *gorm.DB, keyring *Keyring, table string, column string) (int, error) {
+	type encryptedRow struct {
+		ID    uint
+		Value sql.NullString
+	}
+
+	updated := 0
+	lastID := uint(0)
+
+	for {
+		var rows []encryptedRow
+		err := db.
+			Table(table).
+			Select("id, "+column+" AS value").
+			Where("id > ?", lastID).
+			Order("id").
+			Limit(rotationBatchSize).
+			Scan(&rows).
+			Error
+		if err != nil || len(rows) == 0 {
+			return updated, err
+		}
+
+		err = db.Transaction(func(tx *gorm.DB) error {
+			for _, row := range rows {
+				if !row.Value.Valid || row.Value.String == "" {
+					continue
+				}
+
+				rewrapped, changed, err := keyring.Rewrap(row.Value.String)
+				if err != nil {
+					return err
+				}
+				if !changed {
+					continue
+				}
+
+				if err := tx.Table(table).Where("id = ?", row.ID).Update(column, rewrapped).Error; err != nil {
+					return err
+				}
+				updated++
+			}
+
+			return nil
+		})
+		if err != nil {
+			return updated, err
+		}
+
+		lastID = rows[len(rows)-1].ID
+	}
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+	"validator-service/internal/models"
+)
+
+func CreateTenant(db *gorm.DB, tenant *models.Tenant) error {
+	return db.Create(tenant).Error
+}
+
+func GetTenantByID(db *gorm.DB, id uint) (*models.Tenant, error) {
+	var tenant models.Tenant
+	err := db.First(&tenant, id).Error
+
+	return &tenant, err
+}
+
+func CreateAPIKey(db *gorm.DB, apiKey *models.APIKey) error {
+	return db.Create(apiKey).Error
+}
+
+// GetAPIKeyByHash returns the API key with the hash unless it was revoked.
+func GetAPIKeyByHash(db *gorm.DB, keyHash string) (*models.APIKey, error) {
+	var apiKey models.APIKey
+	err := db.
+		Where("key_hash = ?", keyHash).
+		First(&apiKey).
+		Error
+
+	return &apiKey, err
+}
+
+// GetAPIKeys returns the API keys of the tenant that were not revoked, oldest first.
+func GetAPIKeys(db *gorm.DB, tenantID uint) ([]models.APIKey, error) {
+	var apiKeys []models.APIKey
+	err := db.
+		Where("tenant_id = ?", tenantID).
+		Order("id").
+		Find(&apiKeys).
+		Error
+
+	return apiKeys, err
+}
+
+// RevokeAPIKey revokes the API key with the prefix. It returns false when there is no such key.
+func RevokeAPIKey(db *gorm.DB, prefix string) (bool, error) {
+	result := db.
+		Where("prefix = ?", prefix).
+		Delete(&models.APIKey{})
+
+	return result.RowsAffected == 1, result.Error
+}
+
+// RevokeTenantAPIKey revokes the API key with the prefix if it belongs to the tenant. It returns false
+// when the tenant has no such key.
+func RevokeTenantAPIKey(db *gorm.DB, tenantID uint, prefix string) (bool, error) {
+	result := db.
+		Where("tenant_id = ? AND prefix = ?", tenantID, prefix).
+		Delete(&models.APIKey{})
+
+	return result.RowsAffected == 1, result.Error
+}
+
+// AdoptValidatorRequests moves the requests that belong to no tenant, i.e. requests stored before
+// tenants existed, to the tenant.
+func AdoptValidatorRequests(db *gorm.DB, tenantID uint) (int64, error) {
+	result := db.
+		Model(&models.ValidatorRequest{}).
+		Where("tenant_id = ?", 0).
+		Update("tenant_id", tenantID)
+
+	return result.RowsAffected, result.Error
+}
+
+// TenantValidatorRequestIDs is a subquery of the ids of the requests of the tenant, for scoping
+// queries of keys and their history to a tenant.
+func TenantValidatorRequestIDs(db *gorm.DB, tenantID uint) *gorm.DB {
+	return db.
+		Session(&gorm.Session{NewDB: true}).
+		Model(&models.ValidatorRequest{}).
+		Select("id").
+		Where("tenant_id = ?", tenantID)
+}
@@ -0,0 +1,92 @@
+package repository_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"validator-service/internal/models"
+	"validator-service/internal/repository"
+)
+
+func TestAPIKeys(t *testing.T) {
+	db := setupTestDB()
+	tenant := models.Tenant{Name: "tenant"}
+	assert.NoError(t, repository.CreateTenant(db, &tenant))
+
+	apiKey := models.APIKey{TenantID: tenant.ID, Prefix: "vsk_abcdefgh", KeyHash: "hash", Role: models.RoleViewer}
+	assert.NoError(t, repository.CreateAPIKey(db, &apiKey))
+
+	result, err := repository.GetAPIKeyByHash(db, "hash")
+	assert.NoError(t, err)
+	assert.Equal(t, tenant.ID, result.TenantID)
+	assert.Equal(t, models.RoleViewer, result.Role)
+
+	revoked, err := repository.RevokeAPIKey(db, "vsk_abcdefgh")
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+
+	_, err = repository.GetAPIKeyByHash(db, "hash")
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	revoked, err = repository.RevokeAPIKey(db, "vsk_abcdefgh")
+	assert.NoError(t, err)
+	assert.False(t, revoked)
+}
+
+func TestTenantAPIKeys(t *testing.T) {
+	db := setupTestDB()
+	tenant := models.Tenant{Name: "tenant"}
+	assert.NoError(t, repository.CreateTenant(db, &tenant))
+	other := models.Tenant{Name: "other"}
+	assert.NoError(t, repository.CreateTenant(db, &other))
+
+	for _, apiKey := range []models.APIKey{
+		{TenantID: tenant.ID, Prefix: "vsk_admin000", KeyHash: "hash1", Role: models.RoleAdmin},
+		{TenantID: other.ID, Prefix: "vsk_other000", KeyHash: "hash2", Role: models.RoleAdmin},
+		{TenantID: tenant.ID, Prefix: "vsk_viewer00", KeyHash: "hash3", Role: models.RoleViewer},
+	} {
+		assert.NoError(t, repository.CreateAPIKey(db, &apiKey))
+	}
+
+	apiKeys, err := repository.GetAPIKeys(db, tenant.ID)
+	assert.NoError(t, err)
+	assert.Len(t, apiKeys, 2)
+	assert.Equal(t, "vsk_admin000", apiKeys[0].Prefix)
+	assert.Equal(t, "vsk_viewer00", apiKeys[1].Prefix)
+
+	revoked, err := repository.RevokeTenantAPIKey(db, tenant.ID, "vsk_other000")
+	assert.NoError(t, err)
+	assert.False(t, revoked)
+
+	revoked, err = repository.RevokeTenantAPIKey(db, tenant.ID, "vsk_viewer00")
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+
+	apiKeys, err = repository.GetAPIKeys(db, tenant.ID)
+	assert.NoError(t, err)
+	assert.Len(t, apiKeys, 1)
+
+	apiKeys, err = repository.GetAPIKeys(db, other.ID)
+	assert.NoError(t, err)
+	assert.Len(t, apiKeys, 1)
+}
+
+func TestAdoptValidatorRequests(t *testing.T) {
+	db := setupTestDB()
+	owned := newBaseValidator()
+	db.Create(&owned)
+	unowned := models.ValidatorRequest{RequestUUID: "unowned_uuid"}
+	db.Create(&unowned)
+
+	adopted, err := repository.AdoptValidatorRequests(db, otherTenantID)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), adopted)
+
+	result, err := repository.GetValidatorRequestByUUID(db, otherTenantID, "unowned_uuid")
+	assert.NoError(t, err)
+	assert.Equal(t, unowned.ID, result.ID)
+
+	_, err = repository.GetValidatorRequestByUUID(db, testTenantID, owned.RequestUUID)
+	assert.NoError(t, err)
+}
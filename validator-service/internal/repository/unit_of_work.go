@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+	"validator-service/internal/models"
+)
+
+var ErrStatusChanged = errors.New("validator request status changed concurrently")
+
+type statusChange struct {
+	validatorRequest *models.ValidatorRequest
+	from             []models.RequestStatus
+	to               models.RequestStatus
+}
+
+// UnitOfWork collects validator keys, a status change of their request and the jobs queued by it
+// and writes them in one transaction, so that either all changes are stored or none.
+type UnitOfWork struct {
+	db            *gorm.DB
+	validatorKeys []*models.ValidatorKey
+	statusChange  *statusChange
+	jobs          []*models.Job
+}
+
+func NewUnitOfWork(db *gorm.DB) *UnitOfWork {
+	return &UnitOfWork{db: db}
+}
+
+func (u *UnitOfWork) AddValidatorKey(validatorKey *models.ValidatorKey) {
+	u.validatorKeys = append(u.validatorKeys, validatorKey)
+}
+
+// AddJob queues the job together with the other changes, e.g. a notification of the status change.
+func (u *UnitOfWork) AddJob(job *models.Job) {
+	u.jobs = append(u.jobs, job)
+}
+
+// ChangeStatus moves the request to status to if it is in one of the from statuses when the unit of work
+// is committed. Commit fails with ErrStatusChanged otherwise.
+func (u *UnitOfWork) ChangeStatus(validatorRequest *models.ValidatorRequest, from []models.RequestStatus, to models.RequestStatus) {
+	u.statusChange = &statusChange{
+		validatorRequest: validatorRequest,
+		from:             from,
+		to:               to,
+	}
+}
+
+// Commit writes all changes in one transaction. The status is changed first so that the row lock
+// of the request orders the commit with concurrent status changes.
+func (u *UnitOfWork) Commit() error {
+	err := u.db.Transaction(func(tx *gorm.DB) error {
+		if u.statusChange != nil {
+			updated, err := UpdateValidatorRequestStatus(tx, u.statusChange.validatorRequest, u.statusChange.from, u.statusChange.to)
+			if err != nil {
+				return err
+			}
+			if !updated {
+				return ErrStatusChanged
+			}
+		}
+
+		for _, validatorKey := range u.validatorKeys {
+			if err := CreateValidatorKey(tx, validatorKey); err != nil {
+				return err
+			}
+		}
+
+		for _, job := range u.jobs {
+			if err := CreateJob(tx, job); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		// ids assigned by the rolled back inserts are not valid
+		for _, validatorKey := range u.validatorKeys {
+			validatorKey.ID = 0
+		}
+		for _, job := range u.jobs {
+			job.ID = 0
+		}
+
+		return err
+	}
+
+	if u.statusChange != nil {
+		u.statusChange.validatorRequest.Status = u.statusChange.to
+	}
+	u.validatorKeys = nil
+	u.statusChange = nil
+	u.jobs = nil
+
+	return nil
+}
@@ -0,0 +1,162 @@
+package repository_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"validator-service/internal/models"
+	"validator-service/internal/repository"
+)
+
+var errInjected = errors.New("injected failure")
+
+// failOnKey makes inserting the validator key with the given public key fail.
+func failOnKey(t *testing.T, db *gorm.DB, key string) {
+	name := "test:fail_on_key"
+	err := db.Callback().Create().Before("gorm:create").Register(name, func(tx *gorm.DB) {
+		if validatorKey, ok := tx.Statement.Dest.(*models.ValidatorKey); ok && validatorKey.Key == key {
+			tx.AddError(errInjected)
+		}
+	})
+	assert.NoError(t, err)
+
+	t.Cleanup(func() {
+		db.Callback().Create().Remove(name)
+	})
+}
+
+func addKeys(unitOfWork *repository.UnitOfWork, validator *models.ValidatorRequest, size int) []*models.ValidatorKey {
+	var validatorKeys []*models.ValidatorKey
+	for i := 0; i < size; i++ {
+		validatorKey := &models.ValidatorKey{
+			ValidatorRequestID: validator.ID,
+			Key:                fmt.Sprintf("batch-key%d", i),
+			SecretKey:          fmt.Sprintf("batch-secret%d", i),
+			DerivationIndex:    uint(i),
+		}
+		unitOfWork.AddValidatorKey(validatorKey)
+		validatorKeys = append(validatorKeys, validatorKey)
+	}
+
+	return validatorKeys
+}
+
+func newProcessingRequest(t *testing.T, db *gorm.DB) *models.ValidatorRequest {
+	validator := newBaseValidator()
+	validator.Keys = nil
+	validator.Status = models.RequestProcessing
+	assert.NoError(t, repository.CreateValidatorRequest(db, &validator))
+
+	return &validator
+}
+
+func TestUnitOfWorkCommit(t *testing.T) {
+	db := setupTestDB()
+	validator := newProcessingRequest(t, db)
+
+	unitOfWork := repository.NewUnitOfWork(db)
+	validatorKeys := addKeys(unitOfWork, validator, 5)
+	unitOfWork.ChangeStatus(validator, []models.RequestStatus{models.RequestProcessing}, models.RequestSuccessful)
+
+	assert.NoError(t, unitOfWork.Commit())
+	assert.Equal(t, models.RequestSuccessful, validator.Status)
+	for _, validatorKey := range validatorKeys {
+		assert.NotZero(t, validatorKey.ID)
+	}
+
+	result, err := repository.GetValidatorRequestByUUID(db, validator.TenantID, validator.RequestUUID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.RequestSuccessful, result.Status)
+	assert.Len(t, result.Keys, 5)
+	assert.Equal(t, "batch-secret3", result.Keys[3].SecretKey)
+}
+
+func TestUnitOfWorkRollsBackOnFailureMidBatch(t *testing.T) {
+	for _, failingKey := range []string{"batch-key0", "batch-key2", "batch-key4"} {
+		t.Run(failingKey, func(t *testing.T) {
+			db := setupTestDB()
+			validator := newProcessingRequest(t, db)
+			failOnKey(t, db, failingKey)
+
+			unitOfWork := repository.NewUnitOfWork(db)
+			validatorKeys := addKeys(unitOfWork, validator, 5)
+			unitOfWork.ChangeStatus(validator, []models.RequestStatus{models.RequestProcessing}, models.RequestSuccessful)
+
+			err := unitOfWork.Commit()
+			assert.ErrorIs(t, err, errInjected)
+			for _, validatorKey := range validatorKeys {
+				assert.Zero(t, validatorKey.ID)
+			}
+
+			// neither the keys stored before the failure nor the status change are kept
+			count, err := repository.CountValidatorKeys(db, validator.ID)
+			assert.NoError(t, err)
+			assert.Zero(t, count)
+
+			result, err := repository.GetValidatorRequestByID(db, validator.ID)
+			assert.NoError(t, err)
+			assert.Equal(t, models.RequestProcessing, result.Status)
+			assert.Equal(t, models.RequestProcessing, validator.Status)
+		})
+	}
+}
+
+func TestUnitOfWorkCommitsJobs(t *testing.T) {
+	db := setupTestDB()
+	validator := newProcessingRequest(t, db)
+
+	unitOfWork := repository.NewUnitOfWork(db)
+	addKeys(unitOfWork, validator, 2)
+	unitOfWork.ChangeStatus(validator, []models.RequestStatus{models.RequestProcessing}, models.RequestSuccessful)
+	job := &models.Job{Kind: "test", ReferenceID: validator.ID, Status: models.JobPending}
+	unitOfWork.AddJob(job)
+
+	assert.NoError(t, unitOfWork.Commit())
+	assert.NotZero(t, job.ID)
+
+	count, err := repository.CountActiveJobs(db, "test")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestUnitOfWorkRollsBackJobs(t *testing.T) {
+	db := setupTestDB()
+	validator := newProcessingRequest(t, db)
+	failOnKey(t, db, "batch-key1")
+
+	unitOfWork := repository.NewUnitOfWork(db)
+	addKeys(unitOfWork, validator, 2)
+	unitOfWork.ChangeStatus(validator, []models.RequestStatus{models.RequestProcessing}, models.RequestSuccessful)
+	job := &models.Job{Kind: "test", ReferenceID: validator.ID, Status: models.JobPending}
+	unitOfWork.AddJob(job)
+
+	assert.ErrorIs(t, unitOfWork.Commit(), errInjected)
+	assert.Zero(t, job.ID)
+
+	count, err := repository.CountActiveJobs(db, "test")
+	assert.NoError(t, err)
+	assert.Zero(t, count)
+}
+
+func TestUnitOfWorkRejectsChangedStatus(t *testing.T) {
+	db := setupTestDB()
+	validator := newProcessingRequest(t, db)
+
+	cancelled, err := repository.GetValidatorRequestByID(db, validator.ID)
+	assert.NoError(t, err)
+	_, err = repository.UpdateValidatorRequestStatus(db, cancelled, models.RequestsInProgress, models.RequestCancelled)
+	assert.NoError(t, err)
+
+	unitOfWork := repository.NewUnitOfWork(db)
+	addKeys(unitOfWork, validator, 3)
+	unitOfWork.ChangeStatus(validator, []models.RequestStatus{models.RequestProcessing}, models.RequestProcessing)
+
+	assert.ErrorIs(t, unitOfWork.Commit(), repository.ErrStatusChanged)
+
+	count, err := repository.CountValidatorKeys(db, validator.ID)
+	assert.NoError(t, err)
+	assert.Zero(t, count)
+}
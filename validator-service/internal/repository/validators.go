@@ -2,9 +2,22 @@ package repository
 
 import (
 	"gorm.io/gorm"
+	"strings"
+	"time"
 	"validator-service/internal/models"
 )
 
+// ValidatorRequestFilter selects the requests returned by ListValidatorRequests. Zero values do not filter.
+type ValidatorRequestFilter struct {
+	Status        models.RequestStatus
+	FeeRecipient  string
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+	// BeforeID continues a listing after the request with this id.
+	BeforeID uint
+	Limit    int
+}
+
 func CreateValidatorRequest(db *gorm.DB, validator *models.ValidatorRequest) error {
 	return db.Create(validator).Error
 }
@@ -13,11 +26,88 @@ func UpdateValidatorRequest(db *gorm.DB, validator *models.ValidatorRequest) err
 	return db.Save(validator).Error
 }
 
-func GetValidatorRequestByUUID(db *gorm.DB, uuid string) (*models.ValidatorRequest, error) {
+// UpdateValidatorRequestStatus changes the status of the request only if it is still in one of the from statuses.
+// It returns false when the status was changed concurrently, e.g. by a cancellation. The caller updates
+// the status of validator once the change is committed.
+func UpdateValidatorRequestStatus(db *gorm.DB, validator *models.ValidatorRequest, from []models.RequestStatus, to models.RequestStatus) (bool, error) {
+	// the model is not passed to gorm, which would save its preloaded keys and assign the status to it
+	// before the transaction commits
+	result := db.
+		Model(&models.ValidatorRequest{}).
+		Where("id = ? AND status IN ?", validator.ID, from).
+		Update("status", to)
+
+	return result.RowsAffected == 1, result.Error
+}
+
+// AddValidatorsToRequest adds numValidators to a successful request and starts it again. It returns false
+// when the request is no longer successful, e.g. because validators were added to it concurrently. The
+// caller updates validator once the change is committed.
+func AddValidatorsToRequest(db *gorm.DB, validator *models.ValidatorRequest, numValidators uint) (bool, error) {
+	result := db.
+		Model(&models.ValidatorRequest{}).
+		Where("id = ? AND status = ?", validator.ID, models.RequestSuccessful).
+		Updates(map[string]any{
+			"num_validators": gorm.Expr("num_validators + ?", numValidators),
+			"status":         models.RequestStarted,
+			"started_at":     time.Now(),
+		})
+
+	return result.RowsAffected == 1, result.Error
+}
+
+func UpdateNumValidators(db *gorm.DB, validator *models.ValidatorRequest, numValidators uint) error {
+	// passing validator as model would also save its preloaded keys
+	err := db.
+		Model(&models.ValidatorRequest{}).
+		Where("id = ?", validator.ID).
+		Update("num_validators", numValidators).
+		Error
+	if err != nil {
+		return err
+	}
+
+	validator.NumValidators = numValidators
+	return nil
+}
+
+// GetValidatorRequestByUUID returns the request of the tenant with its keys.
+func GetValidatorRequestByUUID(db *gorm.DB, tenantID uint, uuid string) (*models.ValidatorRequest, error) {
+	var validatorRequest models.ValidatorRequest
+	err := db.
+		Preload("Keys", func(db *gorm.DB) *gorm.DB {
+			return db.Order("derivation_index")
+		}).
+		Where("tenant_id = ? AND request_uuid = ?", tenantID, uuid).
+		First(&validatorRequest).
+		Error
+
+	return &validatorRequest, err
+}
+
+// GetValidatorRequestByUUIDWithPublicKeys returns the request of the tenant with its keys for read-only
+// paths. The mnemonic and the secret keys are not loaded, so the result must not be saved.
+func GetValidatorRequestByUUIDWithPublicKeys(db *gorm.DB, tenantID uint, uuid string) (*models.ValidatorRequest, error) {
+	var validatorRequest models.ValidatorRequest
+	err := db.
+		Omit("mnemonic").
+		Preload("Keys", func(db *gorm.DB) *gorm.DB {
+			return db.Omit("secret_key", "withdrawal_secret_key").Order("derivation_index")
+		}).
+		Where("tenant_id = ? AND request_uuid = ?", tenantID, uuid).
+		First(&validatorRequest).
+		Error
+
+	return &validatorRequest, err
+}
+
+// GetValidatorRequestByUUIDWithoutKeys returns the request of the tenant without loading its keys.
+// The mnemonic is not loaded either, so the result must not be saved.
+func GetValidatorRequestByUUIDWithoutKeys(db *gorm.DB, tenantID uint, uuid string) (*models.ValidatorRequest, error) {
 	var validatorRequest models.ValidatorRequest
 	err := db.
-		Preload("Keys").
-		Where("request_uuid = ?", uuid).
+		Omit("mnemonic").
+		Where("tenant_id = ? AND request_uuid = ?", tenantID, uuid).
 		First(&validatorRequest).
 		Error
 
@@ -27,3 +117,157 @@ func GetValidatorRequestByUUID(db *gorm.DB, uuid string) (*models.ValidatorReque
 func CreateValidatorKey(db *gorm.DB, validatorKey *models.ValidatorKey) error {
 	return db.Create(validatorKey).Error
 }
+
+// DeleteValidatorKeysCreatedSince permanently deletes the keys of the request created at or after since.
+func DeleteValidatorKeysCreatedSince(db *gorm.DB, validatorRequestID uint, since time.Time) (int64, error) {
+	result := db.
+		Unscoped().
+		Where("validator_request_id = ? AND created_at >= ?", validatorRequestID, since).
+		Delete(&models.ValidatorKey{})
+
+	return result.RowsAffected, result.Error
+}
+
+// GetValidatorKeyByPubkey returns the key with the public key if it belongs to a request of the tenant.
+func GetValidatorKeyByPubkey(db *gorm.DB, tenantID uint, pubkey string) (*models.ValidatorKey, error) {
+	var validatorKey models.ValidatorKey
+	err := db.
+		Where("key = ? AND validator_request_id IN (?)", pubkey, TenantValidatorRequestIDs(db, tenantID)).
+		First(&validatorKey).
+		Error
+
+	return &validatorKey, err
+}
+
+// GetValidatorKeyByPubkeyWithoutSecrets returns the key with the public key if it belongs to a request of the
+// tenant, for read-only paths. The secret keys are not loaded, so the result must not be saved.
+func GetValidatorKeyByPubkeyWithoutSecrets(db *gorm.DB, tenantID uint, pubkey string) (*models.ValidatorKey, error) {
+	var validatorKey models.ValidatorKey
+	err := db.
+		Omit("secret_key", "withdrawal_secret_key").
+		Where("key = ? AND validator_request_id IN (?)", pubkey, TenantValidatorRequestIDs(db, tenantID)).
+		First(&validatorKey).
+		Error
+
+	return &validatorKey, err
+}
+
+// GetValidatorKeyPubkeys returns the public keys of all keys of the tenant.
+func GetValidatorKeyPubkeys(db *gorm.DB, tenantID uint) ([]string, error) {
+	var pubkeys []string
+	err := db.
+		Model(&models.ValidatorKey{}).
+		Where("validator_request_id IN (?)", TenantValidatorRequestIDs(db, tenantID)).
+		Order("id").
+		Pluck("key", &pubkeys).
+		Error
+
+	return pubkeys, err
+}
+
+// GetValidatorKeysFromIndex returns the public keys and derivation indexes of the keys of the request
+// derived at fromIndex or later, ordered by derivation index. Secret keys are not loaded.
+func GetValidatorKeysFromIndex(db *gorm.DB, validatorRequestID uint, fromIndex uint) ([]models.ValidatorKey, error) {
+	var validatorKeys []models.ValidatorKey
+	err := db.
+		Select("id", "validator_request_id", "key", "derivation_index").
+		Where("validator_request_id = ? AND derivation_index >= ?", validatorRequestID, fromIndex).
+		Order("derivation_index").
+		Find(&validatorKeys).
+		Error
+
+	return validatorKeys, err
+}
+
+func CountValidatorKeys(db *gorm.DB, validatorRequestID uint) (int64, error) {
+	var count int64
+	err := db.
+		Model(&models.ValidatorKey{}).
+		Where("validator_request_id = ?", validatorRequestID).
+		Count(&count).
+		Error
+
+	return count, err
+}
+
+// ListValidatorRequests returns the requests of the tenant matching filter, newest first. Keys and mnemonics are not loaded.
+func ListValidatorRequests(db *gorm.DB, tenantID uint, filter ValidatorRequestFilter) ([]models.ValidatorRequest, error) {
+	query := db.
+		Select("id", "created_at", "updated_at", "tenant_id", "request_uuid", "num_validators", "fee_recipient", "withdrawal_address", "callback_url", "status").
+		Where("tenant_id = ?", tenantID)
+
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.FeeRecipient != "" {
+		query = query.Where("LOWER(fee_recipient) = ?", strings.ToLower(filter.FeeRecipient))
+	}
+	// gorm stores timestamps in the local time zone, which SQLite compares as text
+	if !filter.CreatedAfter.IsZero() {
+		query = query.Where("created_at >= ?", filter.CreatedAfter.Local())
+	}
+	if !filter.CreatedBefore.IsZero() {
+		query = query.Where("created_at < ?", filter.CreatedBefore.Local())
+	}
+	if filter.BeforeID != 0 {
+		query = query.Where("id < ?", filter.BeforeID)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	var validatorRequests []models.ValidatorRequest
+	err := query.
+		Order("id DESC").
+		Find(&validatorRequests).
+		Error
+
+	return validatorRequests, err
+}
+
+func GetValidatorRequestByID(db *gorm.DB, id uint) (*models.ValidatorRequest, error) {
+	var validatorRequest models.ValidatorRequest
+	err := db.First(&validatorRequest, id).Error
+
+	return &validatorRequest, err
+}
+
+// GetValidatorRequestByIDWithoutKeys returns the request without loading its keys. The mnemonic is not
+// loaded either, so the result must not be saved.
+func GetValidatorRequestByIDWithoutKeys(db *gorm.DB, id uint) (*models.ValidatorRequest, error) {
+	var validatorRequest models.ValidatorRequest
+	err := db.
+		Omit("mnemonic").
+		First(&validatorRequest, id).
+		Error
+
+	return &validatorRequest, err
+}
+
+// GetValidatorRequestStatus returns the status and number of validators of the request, e.g. to poll its
+// progress. No other columns are loaded, in particular not the encrypted mnemonic.
+func GetValidatorRequestStatus(db *gorm.DB, id uint) (*models.ValidatorRequest, error) {
+	var validatorRequest models.ValidatorRequest
+	err := db.
+		Select("id", "status", "num_validators").
+		First(&validatorRequest, id).
+		Error
+
+	return &validatorRequest, err
+}
+
+// GetValidatorRequestsWithoutJob returns requests in one of statuses that have no pending or running job of kind,
+// e.g. requests started before the job queue existed.
+func GetValidatorRequestsWithoutJob(db *gorm.DB, statuses []models.RequestStatus, kind string) ([]models.ValidatorRequest, error) {
+	var validatorRequests []models.ValidatorRequest
+	err := db.
+		Where("status IN ?", statuses).
+		Where(
+			"NOT EXISTS (SELECT 1 FROM jobs WHERE jobs.kind = ? AND jobs.reference_id = validator_requests.id AND jobs.status IN ? AND jobs.deleted_at IS NULL)",
+			kind, []models.JobStatus{models.JobPending, models.JobRunning},
+		).
+		Find(&validatorRequests).
+		Error
+
+	return validatorRequests, err
+}
@@ -1,16 +1,25 @@
 package repository_test
 
 import (
+	"fmt"
+	"os"
 	"testing"
+	"time"
 
-	"github.com/glebarez/sqlite"
 	"github.com/stretchr/testify/assert"
 	"gorm.io/gorm"
+	"validator-service/internal/migrations"
 	"validator-service/internal/models"
 	"validator-service/internal/repository"
 )
 
+const (
+	testTenantID  uint = 1
+	otherTenantID uint = 2
+)
+
 var baseValidator = models.ValidatorRequest{
+	TenantID:      testTenantID,
 	RequestUUID:   "random_uuid",
 	NumValidators: 5,
 	Status:        models.RequestStarted,
@@ -24,25 +33,53 @@ var baseValidator = models.ValidatorRequest{
 	},
 }
 
+// newBaseValidator copies baseValidator so that ids assigned on create do not leak between tests.
+func newBaseValidator() models.ValidatorRequest {
+	validator := baseValidator
+	validator.Keys = append([]models.ValidatorKey{}, baseValidator.Keys...)
+	return validator
+}
+
+// setupTestDB connects to an in-memory SQLite database. Set TEST_DB_DRIVER=postgres and
+// TEST_DB_DSN to run the repository tests against PostgreSQL instead.
 func setupTestDB() *gorm.DB {
-	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	keyring, err := repository.NewKeyring(make([]byte, repository.MasterKeyLength))
+	if err != nil {
+		panic("failed to create keyring")
+	}
+	repository.SetKeyring(keyring)
+
+	driver := os.Getenv("TEST_DB_DRIVER")
+	dsn := os.Getenv("TEST_DB_DSN")
+	if driver == "" {
+		driver, dsn = repository.DriverSQLite, ":memory:"
+	}
+
+	db, err := repository.OpenDatabase(driver, dsn)
 	if err != nil {
 		panic("failed to connect database")
 	}
-	db.AutoMigrate(&models.ValidatorRequest{}, &models.ValidatorKey{})
+
+	// start every test from empty tables on a shared database
+	if _, err := migrations.Down(db, len(migrations.All())); err != nil {
+		panic("failed to roll back migrations")
+	}
+	if _, err := migrations.Up(db); err != nil {
+		panic("failed to apply migrations")
+	}
 	return db
 }
 
 func TestCreateValidatorRequest(t *testing.T) {
 	db := setupTestDB()
-	validator := baseValidator
+	validator := newBaseValidator()
 
 	err := repository.CreateValidatorRequest(db, &validator)
 	assert.NoError(t, err)
 	assert.NotZero(t, validator.ID)
 
 	var result models.ValidatorRequest
-	db.Preload("Keys").First(&result, "random_uuid = ?", validator.RequestUUID)
+	db.Preload("Keys").First(&result, "request_uuid = ?", validator.RequestUUID)
 	assert.Equal(t, baseValidator.RequestUUID, result.RequestUUID)
 	assert.Equal(t, baseValidator.NumValidators, result.NumValidators)
 	assert.Equal(t, baseValidator.Status, result.Status)
@@ -52,7 +89,7 @@ func TestCreateValidatorRequest(t *testing.T) {
 
 func TestUpdateValidatorRequest(t *testing.T) {
 	db := setupTestDB()
-	validator := baseValidator
+	validator := newBaseValidator()
 	db.Create(&validator)
 
 	validator.Status = models.RequestSuccessful
@@ -67,10 +104,10 @@ func TestUpdateValidatorRequest(t *testing.T) {
 
 func TestGetValidatorRequestByUUID(t *testing.T) {
 	db := setupTestDB()
-	validator := baseValidator
+	validator := newBaseValidator()
 	db.Create(&validator)
 
-	result, err := repository.GetValidatorRequestByUUID(db, validator.RequestUUID)
+	result, err := repository.GetValidatorRequestByUUID(db, testTenantID, validator.RequestUUID)
 	assert.NoError(t, err)
 	assert.Equal(t, validator.RequestUUID, result.RequestUUID)
 	assert.Equal(t, validator.NumValidators, result.NumValidators)
@@ -79,9 +116,118 @@ func TestGetValidatorRequestByUUID(t *testing.T) {
 	assert.Len(t, result.Keys, 5)
 }
 
+func TestGetValidatorRequestByUUIDWithoutKeys(t *testing.T) {
+	db := setupTestDB()
+	validator := newBaseValidator()
+	db.Create(&validator)
+
+	result, err := repository.GetValidatorRequestByUUIDWithoutKeys(db, testTenantID, validator.RequestUUID)
+	assert.NoError(t, err)
+	assert.Equal(t, validator.ID, result.ID)
+	assert.Equal(t, validator.Status, result.Status)
+	assert.Empty(t, result.Keys)
+	assert.Empty(t, result.Mnemonic)
+
+	_, err = repository.GetValidatorRequestByUUIDWithoutKeys(db, testTenantID, "missing")
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestGetValidatorRequestByUUIDWithPublicKeys(t *testing.T) {
+	db := setupTestDB()
+	validator := newBaseValidator()
+	validator.Mnemonic = "mnemonic"
+	for i := range validator.Keys {
+		validator.Keys[i].SecretKey = "secret"
+		validator.Keys[i].WithdrawalSecretKey = "withdrawal secret"
+		validator.Keys[i].DerivationIndex = uint(len(validator.Keys) - i)
+	}
+	db.Create(&validator)
+
+	result, err := repository.GetValidatorRequestByUUIDWithPublicKeys(db, testTenantID, validator.RequestUUID)
+	assert.NoError(t, err)
+	assert.Equal(t, validator.ID, result.ID)
+	assert.Empty(t, result.Mnemonic)
+	assert.Len(t, result.Keys, 5)
+	assert.Equal(t, "key5", result.Keys[0].Key)
+	assert.Equal(t, "0x127", result.Keys[0].FeeRecipient)
+	for _, validatorKey := range result.Keys {
+		assert.Empty(t, validatorKey.SecretKey)
+		assert.Empty(t, validatorKey.WithdrawalSecretKey)
+	}
+
+	_, err = repository.GetValidatorRequestByUUIDWithPublicKeys(db, otherTenantID, validator.RequestUUID)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestGetValidatorRequestStatus(t *testing.T) {
+	db := setupTestDB()
+	validator := newBaseValidator()
+	validator.Mnemonic = "mnemonic"
+	db.Create(&validator)
+
+	result, err := repository.GetValidatorRequestStatus(db, validator.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, validator.ID, result.ID)
+	assert.Equal(t, validator.Status, result.Status)
+	assert.Equal(t, validator.NumValidators, result.NumValidators)
+	assert.Empty(t, result.Mnemonic)
+	assert.Empty(t, result.RequestUUID)
+
+	_, err = repository.GetValidatorRequestStatus(db, validator.ID+1)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestGetValidatorKeysFromIndex(t *testing.T) {
+	db := setupTestDB()
+	validator := newProcessingRequest(t, db)
+
+	for _, index := range []uint{2, 0, 3, 1} {
+		assert.NoError(t, repository.CreateValidatorKey(db, &models.ValidatorKey{
+			ValidatorRequestID: validator.ID,
+			Key:                fmt.Sprintf("key%d", index),
+			SecretKey:          "secret",
+			DerivationIndex:    index,
+		}))
+	}
+
+	validatorKeys, err := repository.GetValidatorKeysFromIndex(db, validator.ID, 2)
+	assert.NoError(t, err)
+	assert.Len(t, validatorKeys, 2)
+	assert.Equal(t, "key2", validatorKeys[0].Key)
+	assert.Equal(t, uint(3), validatorKeys[1].DerivationIndex)
+	assert.Empty(t, validatorKeys[1].SecretKey)
+}
+
+func TestValidatorRequestsAreScopedToTenant(t *testing.T) {
+	db := setupTestDB()
+	validator := newBaseValidator()
+	db.Create(&validator)
+
+	_, err := repository.GetValidatorRequestByUUID(db, otherTenantID, validator.RequestUUID)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	_, err = repository.GetValidatorRequestByUUIDWithoutKeys(db, otherTenantID, validator.RequestUUID)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	_, err = repository.GetValidatorKeyByPubkey(db, otherTenantID, "key1")
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	requests, err := repository.ListValidatorRequests(db, otherTenantID, repository.ValidatorRequestFilter{})
+	assert.NoError(t, err)
+	assert.Empty(t, requests)
+
+	pubkeys, err := repository.GetValidatorKeyPubkeys(db, otherTenantID)
+	assert.NoError(t, err)
+	assert.Empty(t, pubkeys)
+
+	pubkeys, err = repository.GetValidatorKeyPubkeys(db, testTenantID)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"key1", "key2", "key3", "key4", "key5"}, pubkeys)
+}
+
 func TestCreateValidatorKey(t *testing.T) {
 	db := setupTestDB()
-	validator := baseValidator
+	validator := newBaseValidator()
 	db.Create(&validator)
 
 	validatorKey := models.ValidatorKey{
@@ -97,3 +243,209 @@ func TestCreateValidatorKey(t *testing.T) {
 	assert.Equal(t, validatorKey.Key, result.Key)
 	assert.Equal(t, validatorKey.FeeRecipient, result.FeeRecipient)
 }
+
+func TestUpdateValidatorRequestStatus(t *testing.T) {
+	db := setupTestDB()
+	validator := newBaseValidator()
+	db.Create(&validator)
+
+	updated, err := repository.UpdateValidatorRequestStatus(db, &validator, []models.RequestStatus{models.RequestStarted}, models.RequestCancelled)
+	assert.NoError(t, err)
+	assert.True(t, updated)
+
+	// the request is no longer started, a concurrent worker must not overwrite the cancellation
+	updated, err = repository.UpdateValidatorRequestStatus(db, &validator, []models.RequestStatus{models.RequestStarted}, models.RequestSuccessful)
+	assert.NoError(t, err)
+	assert.False(t, updated)
+
+	var result models.ValidatorRequest
+	db.First(&result, validator.ID)
+	assert.Equal(t, models.RequestCancelled, result.Status)
+}
+
+func TestAddValidatorsToRequest(t *testing.T) {
+	db := setupTestDB()
+	validator := newBaseValidator()
+	validator.Status = models.RequestSuccessful
+	validator.NumValidators = 5
+	db.Create(&validator)
+
+	added, err := repository.AddValidatorsToRequest(db, &validator, 3)
+	assert.NoError(t, err)
+	assert.True(t, added)
+
+	// the request was started again, another addition must not add to it before it completed
+	added, err = repository.AddValidatorsToRequest(db, &validator, 3)
+	assert.NoError(t, err)
+	assert.False(t, added)
+
+	var result models.ValidatorRequest
+	db.First(&result, validator.ID)
+	assert.Equal(t, models.RequestStarted, result.Status)
+	assert.Equal(t, uint(8), result.NumValidators)
+
+	count, err := repository.CountValidatorKeys(db, validator.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), count)
+}
+
+func TestDeleteValidatorKeysCreatedSince(t *testing.T) {
+	db := setupTestDB()
+	validator := newBaseValidator()
+	db.Create(&validator)
+
+	since := time.Now()
+	for _, key := range []string{"key6", "key7"} {
+		err := repository.CreateValidatorKey(db, &models.ValidatorKey{ValidatorRequestID: validator.ID, Key: key})
+		assert.NoError(t, err)
+	}
+
+	deleted, err := repository.DeleteValidatorKeysCreatedSince(db, validator.ID, since)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), deleted)
+
+	count, err := repository.CountValidatorKeys(db, validator.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(len(baseValidator.Keys)), count)
+
+	var unscoped int64
+	db.Unscoped().Model(&models.ValidatorKey{}).Where("key IN ?", []string{"key6", "key7"}).Count(&unscoped)
+	assert.Zero(t, unscoped)
+}
+
+func TestUpdateNumValidatorsKeepsDeletedKeysDeleted(t *testing.T) {
+	db := setupTestDB()
+	validator := newBaseValidator()
+	db.Create(&validator)
+
+	// the preloaded keys of the request must not be written back
+	loaded, err := repository.GetValidatorRequestByUUID(db, testTenantID, validator.RequestUUID)
+	assert.NoError(t, err)
+	_, err = repository.DeleteValidatorKeysCreatedSince(db, validator.ID, time.Time{})
+	assert.NoError(t, err)
+
+	assert.NoError(t, repository.UpdateNumValidators(db, loaded, 0))
+	assert.Equal(t, uint(0), loaded.NumValidators)
+
+	count, err := repository.CountValidatorKeys(db, validator.ID)
+	assert.NoError(t, err)
+	assert.Zero(t, count)
+}
+
+func TestListValidatorRequests(t *testing.T) {
+	db := setupTestDB()
+
+	for i, status := range []models.RequestStatus{models.RequestSuccessful, models.RequestFailed, models.RequestSuccessful, models.RequestProcessing} {
+		validator := models.ValidatorRequest{
+			TenantID:     testTenantID,
+			RequestUUID:  fmt.Sprintf("uuid%d", i),
+			Status:       status,
+			FeeRecipient: fmt.Sprintf("0xABC%d", i%2),
+			CallbackURL:  fmt.Sprintf("https://example.com/%d", i),
+			Mnemonic:     "mnemonic",
+		}
+		assert.NoError(t, repository.CreateValidatorRequest(db, &validator))
+	}
+
+	result, err := repository.ListValidatorRequests(db, testTenantID, repository.ValidatorRequestFilter{})
+	assert.NoError(t, err)
+	assert.Len(t, result, 4)
+	assert.Equal(t, "uuid3", result[0].RequestUUID)
+	assert.Equal(t, "https://example.com/3", result[0].CallbackURL)
+	assert.Empty(t, result[0].Mnemonic)
+
+	result, err = repository.ListValidatorRequests(db, testTenantID, repository.ValidatorRequestFilter{Status: models.RequestSuccessful})
+	assert.NoError(t, err)
+	assert.Len(t, result, 2)
+
+	// fee recipients are matched case-insensitively
+	result, err = repository.ListValidatorRequests(db, testTenantID, repository.ValidatorRequestFilter{FeeRecipient: "0xabc0"})
+	assert.NoError(t, err)
+	assert.Len(t, result, 2)
+	assert.Equal(t, "uuid2", result[0].RequestUUID)
+	assert.Equal(t, "uuid0", result[1].RequestUUID)
+
+	result, err = repository.ListValidatorRequests(db, testTenantID, repository.ValidatorRequestFilter{CreatedAfter: time.Now().Add(-time.Hour), CreatedBefore: time.Now().Add(time.Hour)})
+	assert.NoError(t, err)
+	assert.Len(t, result, 4)
+
+	result, err = repository.ListValidatorRequests(db, testTenantID, repository.ValidatorRequestFilter{CreatedAfter: time.Now().Add(time.Hour)})
+	assert.NoError(t, err)
+	assert.Empty(t, result)
+}
+
+func TestListValidatorRequestsPages(t *testing.T) {
+	db := setupTestDB()
+
+	for i := 0; i < 5; i++ {
+		validator := models.ValidatorRequest{TenantID: testTenantID, RequestUUID: fmt.Sprintf("uuid%d", i), Status: models.RequestSuccessful}
+		assert.NoError(t, repository.CreateValidatorRequest(db, &validator))
+	}
+
+	var uuids []string
+	filter := repository.ValidatorRequestFilter{Limit: 2}
+	for {
+		page, err := repository.ListValidatorRequests(db, testTenantID, filter)
+		assert.NoError(t, err)
+		if len(page) == 0 {
+			break
+		}
+
+		for _, validator := range page {
+			uuids = append(uuids, validator.RequestUUID)
+		}
+		filter.BeforeID = page[len(page)-1].ID
+	}
+
+	assert.Equal(t, []string{"uuid4", "uuid3", "uuid2", "uuid1", "uuid0"}, uuids)
+}
+
+func TestGetValidatorKeyByPubkey(t *testing.T) {
+	db := setupTestDB()
+	validator := newBaseValidator()
+	db.Create(&validator)
+
+	result, err := repository.GetValidatorKeyByPubkey(db, testTenantID, "key3")
+	assert.NoError(t, err)
+	assert.Equal(t, validator.ID, result.ValidatorRequestID)
+	assert.Equal(t, "0x125", result.FeeRecipient)
+
+	_, err = repository.GetValidatorKeyByPubkey(db, testTenantID, "unknown")
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	// the same public key can not be stored twice
+	err = repository.CreateValidatorKey(db, &models.ValidatorKey{ValidatorRequestID: validator.ID, Key: "key3"})
+	assert.ErrorIs(t, err, gorm.ErrDuplicatedKey)
+}
+
+func TestGetValidatorKeyByPubkeyWithoutSecrets(t *testing.T) {
+	db := setupTestDB()
+	validator := newBaseValidator()
+	validator.Keys[3].SecretKey = "secret"
+	validator.Keys[3].WithdrawalSecretKey = "withdrawal secret"
+	db.Create(&validator)
+
+	result, err := repository.GetValidatorKeyByPubkeyWithoutSecrets(db, testTenantID, "key3")
+	assert.NoError(t, err)
+	assert.Equal(t, validator.ID, result.ValidatorRequestID)
+	assert.Equal(t, "0x125", result.FeeRecipient)
+	assert.Empty(t, result.SecretKey)
+	assert.Empty(t, result.WithdrawalSecretKey)
+
+	_, err = repository.GetValidatorKeyByPubkeyWithoutSecrets(db, otherTenantID, "key3")
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestGetValidatorRequestByIDWithoutKeys(t *testing.T) {
+	db := setupTestDB()
+	validator := newBaseValidator()
+	validator.Mnemonic = "mnemonic"
+	db.Create(&validator)
+
+	result, err := repository.GetValidatorRequestByIDWithoutKeys(db, validator.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, validator.RequestUUID, result.RequestUUID)
+	assert.Equal(t, validator.FeeRecipient, result.FeeRecipient)
+	assert.Empty(t, result.Mnemonic)
+	assert.Empty(t, result.Keys)
+}
@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+	"validator-service/internal/models"
+)
+
+func CreateWebhookDelivery(db *gorm.DB, delivery *models.WebhookDelivery) error {
+	return db.Create(delivery).Error
+}
+
+// GetWebhookDeliveries returns the webhook delivery attempts of the request, oldest first.
+func GetWebhookDeliveries(db *gorm.DB, validatorRequestID uint) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	err := db.
+		Where("validator_request_id = ?", validatorRequestID).
+		Order("id").
+		Find(&deliveries).
+		Error
+
+	return deliveries, err
+}
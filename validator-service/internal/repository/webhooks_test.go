@@ -0,0 +1,35 @@
+package repository_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"validator-service/internal/models"
+	"validator-service/internal/repository"
+)
+
+func TestWebhookDeliveries(t *testing.T) {
+	db := setupTestDB()
+	validator := newBaseValidator()
+	db.Create(&validator)
+
+	other := models.ValidatorRequest{RequestUUID: "other_uuid"}
+	db.Create(&other)
+
+	deliveries := []models.WebhookDelivery{
+		{ValidatorRequestID: validator.ID, JobID: 1, Event: models.WebhookRequestSuccessful, Attempt: 1, Error: "connection refused"},
+		{ValidatorRequestID: other.ID, JobID: 2, Event: models.WebhookRequestFailed, Attempt: 1, StatusCode: 200},
+		{ValidatorRequestID: validator.ID, JobID: 1, Event: models.WebhookRequestSuccessful, Attempt: 2, StatusCode: 204},
+	}
+	for i := range deliveries {
+		assert.NoError(t, repository.CreateWebhookDelivery(db, &deliveries[i]))
+	}
+
+	result, err := repository.GetWebhookDeliveries(db, validator.ID)
+	assert.NoError(t, err)
+	assert.Len(t, result, 2)
+	assert.Equal(t, uint(1), result[0].Attempt)
+	assert.Equal(t, "connection refused", result[0].Error)
+	assert.Equal(t, uint(2), result[1].Attempt)
+	assert.Equal(t, 204, result[1].StatusCode)
+}
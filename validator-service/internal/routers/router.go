@@ -3,13 +3,74 @@ package routers
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net/http"
 	"validator-service/internal/handlers"
+	"validator-service/internal/middlewares"
+	"validator-service/internal/models"
 )
 
-func SetupRoutes(r *gin.Engine, h *handlers.Handler) {
-	// Validator endpoints
-	r.POST("/validators", h.CreateValidator)
-	r.GET("/validators/:request_id", h.CheckRequestStatus)
+// route is an authenticated endpoint together with the least role allowed to call it.
+type route struct {
+	method  string
+	path    string
+	role    models.Role
+	handler gin.HandlerFunc
+}
+
+// policy lists the endpoints that require authentication with the least role allowed to call them.
+// Viewers read requests and keys, operators create and change requests and sign with their keys,
+// and admins export secrets, import slashing protection data and manage API keys.
+func policy(h *handlers.Handler) []route {
+	return []route{
+		// Validator endpoints
+		{http.MethodPost, "/validators", models.RoleOperator, h.CreateValidator},
+		{http.MethodGet, "/validators", models.RoleViewer, h.ListValidatorRequests},
+		{http.MethodGet, "/validators/:request_id", models.RoleViewer, h.CheckRequestStatus},
+		{http.MethodGet, "/validators/:request_id/events", models.RoleViewer, h.StreamRequestEvents},
+		{http.MethodDelete, "/validators/:request_id", models.RoleOperator, h.CancelValidatorRequest},
+		{http.MethodGet, "/validators/:request_id/deposit-data", models.RoleViewer, h.GetDepositData},
+		{http.MethodPost, "/validators/:request_id/keys", models.RoleOperator, h.AddValidators},
+		{http.MethodPost, "/validators/:request_id/keystores", models.RoleAdmin, h.ExportKeystores},
+		{http.MethodGet, "/validators/:request_id/fee-recipients", models.RoleViewer, h.GetFeeRecipients},
+		{http.MethodGet, "/validators/:request_id/fee-recipients/history", models.RoleViewer, h.GetFeeRecipientHistory},
+		{http.MethodPatch, "/validators/:request_id/fee-recipient", models.RoleOperator, h.UpdateRequestFeeRecipient},
+		{http.MethodGet, "/validators/:request_id/proposer-config", models.RoleViewer, h.GetRequestProposerConfig},
+		{http.MethodGet, "/validators/:request_id/webhook-deliveries", models.RoleViewer, h.GetWebhookDeliveries},
+
+		// Validator key endpoints
+		{http.MethodGet, "/keys/:pubkey", models.RoleViewer, h.GetValidatorKey},
+		{http.MethodPatch, "/keys/:pubkey/fee-recipient", models.RoleOperator, h.UpdateKeyFeeRecipient},
+		{http.MethodPost, "/keys/:pubkey/voluntary-exit", models.RoleOperator, h.CreateVoluntaryExit},
+		{http.MethodPost, "/keys/:pubkey/bls-to-execution-change", models.RoleOperator, h.CreateBLSToExecutionChange},
+
+		// Validator client configuration endpoints
+		{http.MethodGet, "/proposer-config", models.RoleViewer, h.GetProposerConfig},
+
+		// Web3Signer compatible remote signing endpoints
+		{http.MethodGet, "/api/v1/eth2/publicKeys", models.RoleViewer, h.ListPublicKeys},
+		{http.MethodPost, "/api/v1/eth2/sign/:pubkey", models.RoleOperator, h.Sign},
+
+		// Slashing protection endpoints
+		{http.MethodGet, "/slashing-protection/export", models.RoleViewer, h.ExportSlashingProtection},
+		{http.MethodPost, "/slashing-protection/import", models.RoleAdmin, h.ImportSlashingProtection},
+
+		// API key endpoints
+		{http.MethodGet, "/api-keys", models.RoleAdmin, h.ListAPIKeys},
+		{http.MethodPost, "/api-keys", models.RoleAdmin, h.CreateAPIKey},
+		{http.MethodDelete, "/api-keys/:prefix", models.RoleAdmin, h.RevokeAPIKey},
+	}
+}
+
+// SetupRoutes registers the endpoints. All endpoints except the health, upcheck and metrics endpoints
+// require the tenant authentication of auth and the role of their policy.
+func SetupRoutes(r *gin.Engine, h *handlers.Handler, auth gin.HandlerFunc) {
+	api := r.Group("/", auth)
+	for _, route := range policy(h) {
+		api.Handle(route.method, route.path, middlewares.RequireRole(route.role), route.handler)
+	}
+
+	// Web3Signer upcheck endpoint
+	r.GET("/upcheck", h.Upcheck)
 
 	// Health check endpoint
 	r.GET("/health", h.HealthCheck)
@@ -0,0 +1,137 @@
+package routers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"validator-service/internal/config"
+	"validator-service/internal/eth2"
+	"validator-service/internal/events"
+	"validator-service/internal/handlers"
+	"validator-service/internal/middlewares"
+	"validator-service/internal/migrations"
+	"validator-service/internal/models"
+	"validator-service/internal/queue"
+	"validator-service/internal/repository"
+	"validator-service/internal/routers"
+	"validator-service/internal/services"
+)
+
+const unknownRequestID = "550e8400-e29b-41d4-a716-446655440000"
+
+func setupTestDB(t *testing.T) *gorm.DB {
+	keyring, err := repository.NewKeyring(make([]byte, repository.MasterKeyLength))
+	assert.NoError(t, err)
+	repository.SetKeyring(keyring)
+
+	db, err := repository.OpenDatabase(repository.DriverSQLite, filepath.Join(t.TempDir(), "routers.db"))
+	assert.NoError(t, err)
+
+	_, err = migrations.Up(db)
+	assert.NoError(t, err)
+
+	return db
+}
+
+func setupRouter(t *testing.T, db *gorm.DB) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	network, err := eth2.GetNetwork(eth2.NetworkMainnet)
+	assert.NoError(t, err)
+
+	cfg := &config.Config{
+		Network:                 network,
+		JobMaxAttempts:          1,
+		MaxValidatorsPerRequest: 100,
+		MaxQueueDepth:           100,
+	}
+	h := handlers.CreateNewHandler(db, cfg, queue.NewPool(db, queue.DefaultConfig()), events.NewBus())
+
+	r := gin.New()
+	routers.SetupRoutes(r, h, middlewares.APIKeyAuth(db))
+	return r
+}
+
+// TestRolePolicy calls endpoints with the API keys of every role. Requests with a role that is allowed
+// are let through to the handler, which rejects the empty body or unknown request instead.
+func TestRolePolicy(t *testing.T) {
+	db := setupTestDB(t)
+	r := setupRouter(t, db)
+
+	tenant, adminKey, err := services.CreateTenant(db, "acme")
+	assert.NoError(t, err)
+	_, operatorKey, err := services.CreateAPIKey(db, tenant.ID, models.RoleOperator)
+	assert.NoError(t, err)
+	_, viewerKey, err := services.CreateAPIKey(db, tenant.ID, models.RoleViewer)
+	assert.NoError(t, err)
+	keys := map[models.Role]string{
+		models.RoleViewer:   viewerKey,
+		models.RoleOperator: operatorKey,
+		models.RoleAdmin:    adminKey,
+	}
+
+	tests := []struct {
+		method    string
+		path      string
+		forbidden []models.Role
+	}{
+		{http.MethodGet, "/validators", nil},
+		{http.MethodGet, "/validators/" + unknownRequestID, nil},
+		{http.MethodGet, "/slashing-protection/export", nil},
+		{http.MethodPost, "/validators", []models.Role{models.RoleViewer}},
+		{http.MethodDelete, "/validators/" + unknownRequestID, []models.Role{models.RoleViewer}},
+		{http.MethodPost, "/validators/" + unknownRequestID + "/keys", []models.Role{models.RoleViewer}},
+		{http.MethodPost, "/api/v1/eth2/sign/0x01", []models.Role{models.RoleViewer}},
+		{http.MethodPost, "/validators/" + unknownRequestID + "/keystores", []models.Role{models.RoleViewer, models.RoleOperator}},
+		{http.MethodPost, "/slashing-protection/import", []models.Role{models.RoleViewer, models.RoleOperator}},
+		{http.MethodGet, "/api-keys", []models.Role{models.RoleViewer, models.RoleOperator}},
+		{http.MethodPost, "/api-keys", []models.Role{models.RoleViewer, models.RoleOperator}},
+		{http.MethodDelete, "/api-keys/vs_unknown", []models.Role{models.RoleViewer, models.RoleOperator}},
+	}
+
+	for _, test := range tests {
+		for _, role := range models.Roles {
+			t.Run(string(role)+" "+test.method+" "+test.path, func(t *testing.T) {
+				req := httptest.NewRequest(test.method, test.path, strings.NewReader("{}"))
+				req.Header.Set("Content-Type", "application/json")
+				req.Header.Set("Authorization", "Bearer "+keys[role])
+
+				w := httptest.NewRecorder()
+				r.ServeHTTP(w, req)
+
+				assert.NotEqual(t, http.StatusUnauthorized, w.Code)
+				// the route exists, the unknown request is rejected by the handler instead of the router
+				assert.NotContains(t, w.Body.String(), "404 page not found")
+				for _, forbidden := range test.forbidden {
+					if role == forbidden {
+						assert.Equal(t, http.StatusForbidden, w.Code)
+						assert.Contains(t, w.Body.String(), middlewares.ErrForbidden)
+						return
+					}
+				}
+				assert.NotEqual(t, http.StatusForbidden, w.Code)
+			})
+		}
+	}
+}
+
+func TestUnauthenticatedEndpoints(t *testing.T) {
+	db := setupTestDB(t)
+	r := setupRouter(t, db)
+
+	for _, path := range []string{"/upcheck", "/health"} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, http.StatusOK, w.Code, path)
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/validators", nil))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
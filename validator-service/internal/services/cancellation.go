@@ -0,0 +1,100 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"gorm.io/gorm"
+	"sync"
+	"time"
+	"validator-service/internal/models"
+	"validator-service/internal/repository"
+)
+
+var (
+	ErrValidatorRequestCancelled     = errors.New("validator request cancelled")
+	ErrValidatorRequestNotInProgress = errors.New("validator request is not in progress")
+)
+
+// runningRequests holds the cancel functions of the requests processed by this replica, so that a
+// cancellation stops them immediately. Requests processed by other replicas stop on the next job heartbeat.
+var runningRequests = struct {
+	sync.Mutex
+	cancels map[uint]context.CancelCauseFunc
+}{cancels: map[uint]context.CancelCauseFunc{}}
+
+func registerRunningRequest(ctx context.Context, validatorRequestID uint) (context.Context, func()) {
+	ctx, cancel := context.WithCancelCause(ctx)
+
+	runningRequests.Lock()
+	runningRequests.cancels[validatorRequestID] = cancel
+	runningRequests.Unlock()
+
+	return ctx, func() {
+		runningRequests.Lock()
+		delete(runningRequests.cancels, validatorRequestID)
+		runningRequests.Unlock()
+
+		cancel(nil)
+	}
+}
+
+func stopRunningRequest(validatorRequestID uint) {
+	runningRequests.Lock()
+	cancel, ok := runningRequests.cancels[validatorRequestID]
+	runningRequests.Unlock()
+
+	if ok {
+		cancel(ErrValidatorRequestCancelled)
+	}
+}
+
+// CancelValidatorRequest cancels a request in progress and its job and discards the keys derived by the job.
+// Keys of earlier, completed batches of the request are kept.
+func CancelValidatorRequest(db *gorm.DB, validatorRequest *models.ValidatorRequest) error {
+	err := db.Transaction(func(tx *gorm.DB) error {
+		cancelled, err := repository.UpdateValidatorRequestStatus(tx, validatorRequest, models.RequestsInProgress, models.RequestCancelled)
+		if err != nil {
+			return err
+		}
+		if !cancelled {
+			return ErrValidatorRequestNotInProgress
+		}
+
+		job, err := repository.GetActiveJob(tx, ValidatorRequestJobKind, validatorRequest.ID)
+		switch {
+		case err == nil:
+			if err := repository.CancelJob(tx, job); err != nil {
+				return err
+			}
+		case !errors.Is(err, gorm.ErrRecordNotFound):
+			return err
+		}
+
+		// the keys of the current run are derived after the request was started, whether or not it still has a job
+		return discardKeysCreatedSince(tx, validatorRequest, validatorRequest.StartedAt)
+	})
+	if err != nil {
+		return err
+	}
+
+	validatorRequest.Status = models.RequestCancelled
+	stopRunningRequest(validatorRequest.ID)
+	publishStatus(db, validatorRequest)
+
+	return nil
+}
+
+// discardKeysCreatedSince deletes the keys of the request derived since the given time and updates
+// the number of validators of the request to the keys that are left.
+func discardKeysCreatedSince(tx *gorm.DB, validatorRequest *models.ValidatorRequest, since time.Time) error {
+	if _, err := repository.DeleteValidatorKeysCreatedSince(tx, validatorRequest.ID, since); err != nil {
+		return err
+	}
+
+	remainingKeys, err := repository.CountValidatorKeys(tx, validatorRequest.ID)
+	if err != nil {
+		return err
+	}
+
+	return repository.UpdateNumValidators(tx, validatorRequest, uint(remainingKeys))
+}
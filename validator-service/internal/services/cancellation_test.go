@@ -0,0 +1,182 @@
+package services_test
+
+import (
+	"context"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"validator-service/internal/events"
+	"validator-service/internal/keys"
+	"validator-service/internal/migrations"
+	"validator-service/internal/models"
+	"validator-service/internal/queue"
+	"validator-service/internal/repository"
+	"validator-service/internal/services"
+)
+
+// setupTestDB uses a database file because requests are processed while they are cancelled from another connection.
+func setupTestDB(t *testing.T) *gorm.DB {
+	keyring, err := repository.NewKeyring(make([]byte, repository.MasterKeyLength))
+	assert.NoError(t, err)
+	repository.SetKeyring(keyring)
+
+	db, err := repository.OpenDatabase(repository.DriverSQLite, filepath.Join(t.TempDir(), "services.db"))
+	assert.NoError(t, err)
+
+	_, err = migrations.Up(db)
+	assert.NoError(t, err)
+
+	return db
+}
+
+func newValidatorRequest(t *testing.T, db *gorm.DB, numValidators uint, validatorKeys []models.ValidatorKey) *models.ValidatorRequest {
+	mnemonic, err := keys.GenerateMnemonic()
+	assert.NoError(t, err)
+
+	validatorRequest := &models.ValidatorRequest{
+		TenantID:      1,
+		RequestUUID:   "test_uuid",
+		NumValidators: numValidators,
+		Status:        models.RequestStarted,
+		StartedAt:     time.Now(),
+		Mnemonic:      mnemonic,
+		Keys:          validatorKeys,
+	}
+	assert.NoError(t, repository.CreateValidatorRequest(db, validatorRequest))
+
+	return validatorRequest
+}
+
+func TestCancelRunningValidatorRequest(t *testing.T) {
+	db := setupTestDB(t)
+	bus := events.NewBus()
+	services.SetEventBus(bus)
+	t.Cleanup(func() { services.SetEventBus(events.NewBus()) })
+
+	// keys of an earlier, completed batch of the request, e.g. before validators were added to it
+	completedAt := time.Now().Add(-time.Hour)
+	validatorRequest := newValidatorRequest(t, db, 200, []models.ValidatorKey{
+		{Key: "0x01", DerivationIndex: 0, Model: gorm.Model{CreatedAt: completedAt}},
+		{Key: "0x02", DerivationIndex: 1, Model: gorm.Model{CreatedAt: completedAt}},
+	})
+
+	job := queue.NewJob(services.ValidatorRequestJobKind, validatorRequest.ID, 1)
+	assert.NoError(t, repository.CreateJob(db, job))
+
+	subscription := bus.Subscribe(validatorRequest.ID)
+	defer subscription.Close()
+
+	processed := make(chan error, 1)
+	go func() {
+		processed <- services.NewValidatorRequestJob(db).Process(context.Background(), job)
+	}()
+
+	// cancel once the job stored its first batch
+	waitForEvent(t, subscription, events.KeyGenerated)
+
+	running, err := repository.GetValidatorRequestByID(db, validatorRequest.ID)
+	assert.NoError(t, err)
+	assert.NoError(t, services.CancelValidatorRequest(db, running))
+
+	select {
+	case err := <-processed:
+		assert.Error(t, err)
+	case <-time.After(10 * time.Second):
+		t.Fatal("job did not stop after the request was cancelled")
+	}
+
+	result, err := repository.GetValidatorRequestByID(db, validatorRequest.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.RequestCancelled, result.Status)
+	assert.Equal(t, uint(2), result.NumValidators)
+
+	remainingKeys, err := repository.GetValidatorKeysFromIndex(db, validatorRequest.ID, 0)
+	assert.NoError(t, err)
+	assert.Len(t, remainingKeys, 2)
+	for _, validatorKey := range remainingKeys {
+		assert.Contains(t, []string{"0x01", "0x02"}, validatorKey.Key)
+	}
+
+	var cancelledJob models.Job
+	assert.NoError(t, db.First(&cancelledJob, job.ID).Error)
+	assert.Equal(t, models.JobCancelled, cancelledJob.Status)
+
+	err = services.CancelValidatorRequest(db, result)
+	assert.ErrorIs(t, err, services.ErrValidatorRequestNotInProgress)
+}
+
+func TestCancelValidatorRequestWithoutJob(t *testing.T) {
+	db := setupTestDB(t)
+
+	completedAt := time.Now().Add(-time.Hour)
+	validatorRequest := newValidatorRequest(t, db, 4, []models.ValidatorKey{
+		{Key: "0x01", DerivationIndex: 0, Model: gorm.Model{CreatedAt: completedAt}},
+		{Key: "0x02", DerivationIndex: 1, Model: gorm.Model{CreatedAt: completedAt}},
+	})
+
+	// keys derived by the current run, whose job is gone, e.g. because it was recovered on another replica
+	for index, key := range []string{"0x03", "0x04"} {
+		validatorKey := &models.ValidatorKey{ValidatorRequestID: validatorRequest.ID, Key: key, DerivationIndex: uint(index) + 2}
+		assert.NoError(t, repository.CreateValidatorKey(db, validatorKey))
+	}
+
+	// the progress of the run updates the request after its keys were stored
+	processing, err := repository.UpdateValidatorRequestStatus(db, validatorRequest, models.RequestsInProgress, models.RequestProcessing)
+	assert.NoError(t, err)
+	assert.True(t, processing)
+
+	running, err := repository.GetValidatorRequestByID(db, validatorRequest.ID)
+	assert.NoError(t, err)
+	assert.NoError(t, services.CancelValidatorRequest(db, running))
+
+	result, err := repository.GetValidatorRequestByID(db, validatorRequest.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.RequestCancelled, result.Status)
+	assert.Equal(t, uint(2), result.NumValidators)
+
+	remainingKeys, err := repository.GetValidatorKeysFromIndex(db, validatorRequest.ID, 0)
+	assert.NoError(t, err)
+	assert.Len(t, remainingKeys, 2)
+	for _, validatorKey := range remainingKeys {
+		assert.Contains(t, []string{"0x01", "0x02"}, validatorKey.Key)
+	}
+}
+
+func TestCancelledProcessingReleasesKeyGenerationWorkers(t *testing.T) {
+	db := setupTestDB(t)
+	services.SetKeyGenerationWorkers(1)
+	t.Cleanup(func() { services.SetKeyGenerationWorkers(runtime.NumCPU()) })
+
+	validatorRequest := newValidatorRequest(t, db, 1, nil)
+
+	// a cancelled context races with the free worker slot
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	for range 20 {
+		err := services.ProcessValidatorRequest(cancelled, db, validatorRequest)
+		assert.ErrorIs(t, err, context.Canceled)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	assert.NoError(t, services.ProcessValidatorRequest(ctx, db, validatorRequest))
+	assert.Equal(t, models.RequestSuccessful, validatorRequest.Status)
+}
+
+func waitForEvent(t *testing.T, subscription *events.Subscription, eventType events.Type) {
+	timeout := time.After(10 * time.Second)
+	for {
+		select {
+		case event := <-subscription.C:
+			if event.Type == eventType {
+				return
+			}
+		case <-timeout:
+			t.Fatalf("no %s event", eventType)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package services
+
+import (
+	"encoding/hex"
+	"strings"
+	"validator-service/internal/eth2"
+	"validator-service/internal/keys"
+	"validator-service/internal/models"
+)
+
+// BuildDepositData signs a 32 ETH deposit for every key of the request on the given network.
+func BuildDepositData(validatorRequest *models.ValidatorRequest, network *eth2.Network) ([]*eth2.DepositData, error) {
+	depositData := make([]*eth2.DepositData, 0, len(validatorRequest.Keys))
+
+	for _, validatorKey := range validatorRequest.Keys {
+		keypair, err := keys.KeypairFromSecretHex(validatorKey.SecretKey)
+		if err != nil {
+			return nil, err
+		}
+
+		credentials, err := keyWithdrawalCredentials(validatorRequest, &validatorKey)
+		if err != nil {
+			return nil, err
+		}
+
+		depositData = append(depositData, eth2.NewDepositData(keypair, credentials, eth2.MaxEffectiveBalanceGwei, network))
+	}
+
+	return depositData, nil
+}
+
+// keyWithdrawalCredentials returns the stored withdrawal credentials of the key, deriving them
+// from the request for keys created before withdrawal credentials were recorded.
+func keyWithdrawalCredentials(validatorRequest *models.ValidatorRequest, validatorKey *models.ValidatorKey) ([32]byte, error) {
+	var credentials [32]byte
+
+	if validatorKey.WithdrawalCredentials == "" {
+		seed, err := keys.SeedFromMnemonic(validatorRequest.Mnemonic, "")
+		if err != nil {
+			return credentials, err
+		}
+
+		credentials, _, err = withdrawalCredentials(seed, validatorKey.DerivationIndex, validatorRequest.WithdrawalAddress)
+		return credentials, err
+	}
+
+	_, err := hex.Decode(credentials[:], []byte(strings.TrimPrefix(validatorKey.WithdrawalCredentials, "0x")))
+	return credentials, err
+}
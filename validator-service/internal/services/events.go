@@ -0,0 +1,47 @@
+package services
+
+import (
+	"gorm.io/gorm"
+	"log"
+	"validator-service/internal/events"
+	"validator-service/internal/models"
+	"validator-service/internal/repository"
+)
+
+var eventBus = events.NewBus()
+
+// SetEventBus sets the bus that the progress of requests processed by this replica is published to.
+// It must be called before requests are processed.
+func SetEventBus(bus *events.Bus) {
+	eventBus = bus
+}
+
+// publishStatus publishes the status of the request together with the number of its stored keys.
+func publishStatus(db *gorm.DB, validatorRequest *models.ValidatorRequest) {
+	keysGenerated, err := repository.CountValidatorKeys(db, validatorRequest.ID)
+	if err != nil {
+		log.Printf("%s, requestID: %s, error: %v", ErrCountingValidatorKeys, validatorRequest.RequestUUID, err)
+		return
+	}
+
+	publishProgress(validatorRequest, uint(keysGenerated))
+}
+
+func publishProgress(validatorRequest *models.ValidatorRequest, keysGenerated uint) {
+	eventBus.Publish(events.Event{
+		Type:               events.StatusChanged,
+		ValidatorRequestID: validatorRequest.ID,
+		Status:             validatorRequest.Status,
+		KeysGenerated:      keysGenerated,
+		KeysTotal:          validatorRequest.NumValidators,
+	})
+}
+
+func publishKey(validatorKey *models.ValidatorKey) {
+	eventBus.Publish(events.Event{
+		Type:               events.KeyGenerated,
+		ValidatorRequestID: validatorKey.ValidatorRequestID,
+		Pubkey:             validatorKey.Key,
+		DerivationIndex:    validatorKey.DerivationIndex,
+	})
+}
@@ -0,0 +1,22 @@
+package services
+
+import (
+	"validator-service/internal/eth2"
+	"validator-service/internal/keys"
+	"validator-service/internal/models"
+)
+
+// SignVoluntaryExit signs an exit of the validator with the key and index, valid from epoch on.
+func SignVoluntaryExit(validatorKey *models.ValidatorKey, validatorIndex uint64, epoch uint64, network *eth2.Network) (*eth2.SignedVoluntaryExit, error) {
+	keypair, err := keys.KeypairFromSecretHex(validatorKey.SecretKey)
+	if err != nil {
+		return nil, err
+	}
+
+	exit := eth2.VoluntaryExit{
+		Epoch:          eth2.Uint64(epoch),
+		ValidatorIndex: eth2.Uint64(validatorIndex),
+	}
+
+	return eth2.NewSignedVoluntaryExit(keypair, exit, network), nil
+}
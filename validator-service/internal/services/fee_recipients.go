@@ -0,0 +1,88 @@
+package services
+
+import (
+	"errors"
+	"gorm.io/gorm"
+	"validator-service/internal/models"
+	"validator-service/internal/repository"
+)
+
+var ErrValidatorRequestInProgress = errors.New("validator request is in progress")
+
+// UpdateRequestFeeRecipient sets the fee recipient of the request and of all of its keys and records
+// every changed value in the fee recipient history.
+func UpdateRequestFeeRecipient(db *gorm.DB, validatorRequest *models.ValidatorRequest, feeRecipient string) error {
+	err := db.Transaction(func(tx *gorm.DB) error {
+		err := repository.UpdateRequestFeeRecipient(tx, validatorRequest, feeRecipient)
+		if errors.Is(err, repository.ErrStatusChanged) {
+			return ErrValidatorRequestInProgress
+		}
+		if err != nil {
+			return err
+		}
+
+		validatorKeys, err := repository.GetValidatorKeys(tx, validatorRequest.ID)
+		if err != nil {
+			return err
+		}
+
+		var changes []models.FeeRecipientChange
+		if validatorRequest.FeeRecipient != feeRecipient {
+			changes = append(changes, models.FeeRecipientChange{
+				ValidatorRequestID: validatorRequest.ID,
+				OldFeeRecipient:    validatorRequest.FeeRecipient,
+				NewFeeRecipient:    feeRecipient,
+			})
+		}
+
+		for i := range validatorKeys {
+			if validatorKeys[i].FeeRecipient != feeRecipient {
+				changes = append(changes, models.FeeRecipientChange{
+					ValidatorRequestID: validatorRequest.ID,
+					ValidatorKeyID:     &validatorKeys[i].ID,
+					OldFeeRecipient:    validatorKeys[i].FeeRecipient,
+					NewFeeRecipient:    feeRecipient,
+				})
+			}
+		}
+
+		if err := repository.UpdateValidatorKeysFeeRecipient(tx, validatorRequest.ID, feeRecipient); err != nil {
+			return err
+		}
+
+		return repository.CreateFeeRecipientChanges(tx, changes)
+	})
+	if err != nil {
+		return err
+	}
+
+	validatorRequest.FeeRecipient = feeRecipient
+	return nil
+}
+
+// UpdateKeyFeeRecipient sets the fee recipient of a single key and records the change in the fee recipient history.
+// The fee recipient of the request stays the default for keys added later.
+func UpdateKeyFeeRecipient(db *gorm.DB, validatorKey *models.ValidatorKey, feeRecipient string) error {
+	if validatorKey.FeeRecipient == feeRecipient {
+		return nil
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := repository.UpdateValidatorKeyFeeRecipient(tx, validatorKey, feeRecipient); err != nil {
+			return err
+		}
+
+		return repository.CreateFeeRecipientChanges(tx, []models.FeeRecipientChange{{
+			ValidatorRequestID: validatorKey.ValidatorRequestID,
+			ValidatorKeyID:     &validatorKey.ID,
+			OldFeeRecipient:    validatorKey.FeeRecipient,
+			NewFeeRecipient:    feeRecipient,
+		}})
+	})
+	if err != nil {
+		return err
+	}
+
+	validatorKey.FeeRecipient = feeRecipient
+	return nil
+}
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"log"
+	"validator-service/internal/models"
+	"validator-service/internal/queue"
+	"validator-service/internal/repository"
+)
+
+const ValidatorRequestJobKind = "validator_request"
+
+const (
+	ErrLoadingValidatorRequest     = "Failed to load validator request"
+	ErrEnqueuingValidatorRequest   = "Failed to enqueue validator request"
+	ErrRecoveringValidatorRequests = "Failed to recover validator requests"
+)
+
+// ValidatorRequestJob processes validator requests queued with EnqueueValidatorRequest.
+type ValidatorRequestJob struct {
+	db *gorm.DB
+}
+
+func NewValidatorRequestJob(db *gorm.DB) *ValidatorRequestJob {
+	return &ValidatorRequestJob{db: db}
+}
+
+func (j *ValidatorRequestJob) Process(ctx context.Context, job *models.Job) error {
+	validatorRequest, err := repository.GetValidatorRequestByID(j.db, job.ReferenceID)
+	if err != nil {
+		log.Printf("%s, id: %d, error: %v", ErrLoadingValidatorRequest, job.ReferenceID, err)
+		return err
+	}
+
+	ctx, stop := registerRunningRequest(ctx, validatorRequest.ID)
+	defer stop()
+
+	return ProcessValidatorRequest(ctx, j.db, validatorRequest)
+}
+
+// Dead marks the request failed once its job ran out of attempts.
+func (j *ValidatorRequestJob) Dead(job *models.Job, err error) {
+	validatorRequest, loadErr := repository.GetValidatorRequestByIDWithoutKeys(j.db, job.ReferenceID)
+	if loadErr != nil {
+		log.Printf("%s, id: %d, error: %v", ErrLoadingValidatorRequest, job.ReferenceID, loadErr)
+		return
+	}
+
+	if err := updateValidatorStatus(j.db, validatorRequest, models.RequestFailed); err != nil {
+		log.Println(err)
+	}
+}
+
+// EnqueueValidatorRequest queues the processing of the request. Pass the transaction that created
+// or updated the request so that the request is never stored without its job.
+func EnqueueValidatorRequest(tx *gorm.DB, validatorRequest *models.ValidatorRequest, maxAttempts uint) error {
+	return repository.CreateJob(tx, queue.NewJob(ValidatorRequestJobKind, validatorRequest.ID, maxAttempts))
+}
+
+// RecoverValidatorRequests queues requests in progress that have no job, e.g. requests that were
+// being processed in goroutines by a version of the service without the job queue.
+func RecoverValidatorRequests(db *gorm.DB, maxAttempts uint) (int, error) {
+	validatorRequests, err := repository.GetValidatorRequestsWithoutJob(db, models.RequestsInProgress, ValidatorRequestJobKind)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range validatorRequests {
+		if err := EnqueueValidatorRequest(db, &validatorRequests[i], maxAttempts); err != nil {
+			log.Printf("%s, requestID: %s, error: %v", ErrEnqueuingValidatorRequest, validatorRequests[i].RequestUUID, err)
+			return i, err
+		}
+	}
+
+	return len(validatorRequests), nil
+}
@@ -0,0 +1,26 @@
+package services
+
+import (
+	"validator-service/internal/keys"
+	"validator-service/internal/models"
+)
+
+func ExportKeystores(validatorRequest *models.ValidatorRequest, password string, kdf string) ([]*keys.Keystore, error) {
+	keystores := make([]*keys.Keystore, 0, len(validatorRequest.Keys))
+
+	for _, validatorKey := range validatorRequest.Keys {
+		keypair, err := keys.KeypairFromSecretHex(validatorKey.SecretKey)
+		if err != nil {
+			return nil, err
+		}
+
+		keystore, err := keys.EncryptKeystore(keypair, password, keys.SigningKeyPath(validatorKey.DerivationIndex), kdf)
+		if err != nil {
+			return nil, err
+		}
+
+		keystores = append(keystores, keystore)
+	}
+
+	return keystores, nil
+}
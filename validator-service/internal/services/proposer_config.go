@@ -0,0 +1,16 @@
+package services
+
+import (
+	"validator-service/internal/eth2"
+	"validator-service/internal/models"
+)
+
+// BuildProposerConfig returns the proposer config with the fee recipient of each of the keys.
+func BuildProposerConfig(defaultFeeRecipient string, validatorKeys []models.ValidatorKey) *eth2.ProposerConfig {
+	proposerConfig := eth2.NewProposerConfig(defaultFeeRecipient)
+	for _, validatorKey := range validatorKeys {
+		proposerConfig.SetFeeRecipient(validatorKey.Key, validatorKey.FeeRecipient)
+	}
+
+	return proposerConfig
+}
@@ -0,0 +1,53 @@
+package services
+
+import (
+	"gorm.io/gorm"
+	"validator-service/internal/eth2"
+	"validator-service/internal/keys"
+	"validator-service/internal/repository"
+	"validator-service/internal/slashing"
+)
+
+// SignMessage signs the message of a Web3Signer signing request with the stored key of pubkey.
+// It returns gorm.ErrRecordNotFound for keys that are not stored for the tenant and an error wrapping
+// slashing.ErrNotSafeToSign for slashable blocks and attestations.
+func SignMessage(db *gorm.DB, tenantID uint, pubkey string, request *eth2.SigningRequest, network *eth2.Network) ([keys.SignatureLength]byte, error) {
+	var signature [keys.SignatureLength]byte
+
+	validatorKey, err := repository.GetValidatorKeyByPubkey(db, tenantID, pubkey)
+	if err != nil {
+		return signature, err
+	}
+
+	signingRoot, err := request.ComputeSigningRoot(network)
+	if err != nil {
+		return signature, err
+	}
+
+	keypair, err := keys.KeypairFromSecretHex(validatorKey.SecretKey)
+	if err != nil {
+		return signature, err
+	}
+
+	if err := checkSlashingProtection(db, validatorKey.ID, request, signingRoot); err != nil {
+		return signature, err
+	}
+
+	return keypair.Sign(signingRoot[:]), nil
+}
+
+// checkSlashingProtection records blocks and attestations before they are signed and refuses slashable ones.
+// Other messages can not get a validator slashed.
+func checkSlashingProtection(db *gorm.DB, validatorKeyID uint, request *eth2.SigningRequest, signingRoot eth2.Root) error {
+	switch request.Type {
+	case eth2.SignTypeBlockV2:
+		slot := request.BeaconBlock.BlockHeader.Slot
+		return slashing.CheckAndRecordBlock(db, validatorKeyID, uint64(slot), signingRoot)
+
+	case eth2.SignTypeAttestation:
+		attestation := request.Attestation
+		return slashing.CheckAndRecordAttestation(db, validatorKeyID, uint64(attestation.Source.Epoch), uint64(attestation.Target.Epoch), signingRoot)
+	}
+
+	return nil
+}
@@ -0,0 +1,109 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"gorm.io/gorm"
+	"slices"
+	"strings"
+	"validator-service/internal/models"
+	"validator-service/internal/repository"
+)
+
+// APIKeyPrefix starts every API key, so that leaked keys are easy to recognise.
+const APIKeyPrefix = "vsk_"
+
+const (
+	apiKeyLength = 32
+	// apiKeyDisplayLength is the length of the start of a key that is stored to tell keys apart.
+	apiKeyDisplayLength = len(APIKeyPrefix) + 8
+)
+
+var (
+	ErrInvalidAPIKey = errors.New("invalid API key")
+	ErrInvalidRole   = errors.New("invalid role")
+)
+
+// CreateTenant creates a tenant together with its first API key, an admin key. The key is only
+// returned here, the service only stores its hash.
+func CreateTenant(db *gorm.DB, name string) (*models.Tenant, string, error) {
+	tenant := &models.Tenant{Name: name}
+	var key string
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := repository.CreateTenant(tx, tenant); err != nil {
+			return err
+		}
+
+		var err error
+		_, key, err = createAPIKey(tx, tenant.ID, models.RoleAdmin)
+		return err
+	})
+	if err != nil {
+		tenant.ID = 0
+		return nil, "", err
+	}
+
+	return tenant, key, nil
+}
+
+// CreateAPIKey creates another API key of the tenant with the role, e.g. to rotate keys. It returns
+// ErrInvalidRole for unknown roles and gorm.ErrRecordNotFound for unknown tenants.
+func CreateAPIKey(db *gorm.DB, tenantID uint, role models.Role) (*models.APIKey, string, error) {
+	if !slices.Contains(models.Roles, role) {
+		return nil, "", ErrInvalidRole
+	}
+
+	if _, err := repository.GetTenantByID(db, tenantID); err != nil {
+		return nil, "", err
+	}
+
+	return createAPIKey(db, tenantID, role)
+}
+
+// AuthenticateAPIKey returns the stored API key matching key. It fails with ErrInvalidAPIKey for
+// unknown and revoked keys.
+func AuthenticateAPIKey(db *gorm.DB, key string) (*models.APIKey, error) {
+	if !strings.HasPrefix(key, APIKeyPrefix) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	apiKey, err := repository.GetAPIKeyByHash(db, hashAPIKey(key))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrInvalidAPIKey
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return apiKey, nil
+}
+
+func createAPIKey(db *gorm.DB, tenantID uint, role models.Role) (*models.APIKey, string, error) {
+	random := make([]byte, apiKeyLength)
+	if _, err := rand.Read(random); err != nil {
+		return nil, "", err
+	}
+	key := APIKeyPrefix + base64.RawURLEncoding.EncodeToString(random)
+
+	apiKey := &models.APIKey{
+		TenantID: tenantID,
+		Prefix:   key[:apiKeyDisplayLength],
+		KeyHash:  hashAPIKey(key),
+		Role:     role,
+	}
+	if err := repository.CreateAPIKey(db, apiKey); err != nil {
+		return nil, "", err
+	}
+
+	return apiKey, key, nil
+}
+
+// hashAPIKey hashes a key for storage. API keys are random, so a fast hash does not make them easier to guess.
+func hashAPIKey(key string) string {
+	digest := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(digest[:])
+}
@@ -1,106 +1,246 @@
 package services
 
 import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
 	"gorm.io/gorm"
 	"log"
-	"math/rand"
-	"strings"
+	"runtime"
 	"sync"
-	"time"
+	"validator-service/internal/eth2"
+	"validator-service/internal/keys"
 	"validator-service/internal/models"
 	"validator-service/internal/repository"
+	"validator-service/internal/utils"
 )
 
-const DelayTime = 20 * time.Millisecond
-
 const (
 	ErrCreatingValidator              = "Failed to create validator"
 	ErrCreatingValidatorKey           = "Failed to create validator key"
-	ErrGeneratingRandomString         = "Failed to generate random string"
+	ErrCountingValidatorKeys          = "Failed to count validator keys"
+	ErrDerivingSeed                   = "Failed to derive seed from mnemonic"
+	ErrDerivingKeypair                = "Failed to derive BLS keypair"
+	ErrDerivingWithdrawalCredentials  = "Failed to derive withdrawal credentials"
 	ErrUpdatingValidatorRequestStatus = "Failed to update validator request status"
 )
 
-type Result struct {
-	key string
-	err error
+// KeyBatchSize is the number of keys derived and stored at a time.
+const KeyBatchSize uint = 16
+
+// keyGenerationSlots bounds the number of keys derived concurrently across all requests.
+var keyGenerationSlots = make(chan struct{}, runtime.NumCPU())
+
+// SetKeyGenerationWorkers sets the number of keys derived concurrently across all requests.
+// It must be called before requests are processed.
+func SetKeyGenerationWorkers(workers int) {
+	keyGenerationSlots = make(chan struct{}, workers)
 }
 
-func ProcessValidatorRequest(db *gorm.DB, validatorRequest *models.ValidatorRequest, requestLock *sync.Mutex) {
-	var keys []string
-	var errors []error
-	var wg sync.WaitGroup
-	var keyLock sync.Mutex
+type derivedValidator struct {
+	keypair               *keys.Keypair
+	withdrawalCredentials [32]byte
+	// withdrawalKeypair is nil for validators with an execution withdrawal address.
+	withdrawalKeypair *keys.Keypair
+}
 
-	for i := uint(0); i < validatorRequest.NumValidators; i++ {
-		wg.Add(1)
-		go createValidator(&keys, &errors, &wg, &keyLock)
+// ProcessValidatorRequest derives the keys of all validators of the request that do not exist yet.
+// Keys are derived from the request mnemonic at the EIP-2334 signing paths m/12381/3600/i/0/0,
+// continuing at the next unused index so that validators can be added to a request later and
+// a retried request continues where the previous attempt stopped. Keys are stored in batches
+// while the request is processing, so that clients can follow the progress. Every batch is written
+// atomically with the status of the request and is rejected once the request was cancelled.
+// Stored keys and status changes are published to the event bus once they are committed.
+func ProcessValidatorRequest(ctx context.Context, db *gorm.DB, validatorRequest *models.ValidatorRequest) error {
+	if err := updateValidatorStatus(db, validatorRequest, models.RequestProcessing); err != nil {
+		return err
 	}
 
-	wg.Wait() // wait for all validators to be created
-
-	if len(errors) > 0 {
-		log.Printf("%s: %v", ErrCreatingValidator, errors)
-		updateValidatorStatus(db, validatorRequest, models.RequestFailed, requestLock)
+	existingKeys, err := repository.CountValidatorKeys(db, validatorRequest.ID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", ErrCountingValidatorKeys, err)
+	}
 
-		return
+	seed, err := keys.SeedFromMnemonic(validatorRequest.Mnemonic, "")
+	if err != nil {
+		return fmt.Errorf("%s: %w", ErrDerivingSeed, err)
 	}
 
-	for i := 0; i < len(keys); i++ {
-		validatorKey := models.ValidatorKey{
-			ValidatorRequestID: validatorRequest.ID,
-			Key:                keys[i],
-			FeeRecipient:       validatorRequest.FeeRecipient,
+	for index := min(uint(existingKeys), validatorRequest.NumValidators); index < validatorRequest.NumValidators; index += KeyBatchSize {
+		count := min(KeyBatchSize, validatorRequest.NumValidators-index)
+
+		validators, err := deriveValidators(ctx, seed, index, count, validatorRequest.WithdrawalAddress)
+		if err != nil {
+			return err
+		}
+
+		// the keys of a batch are stored together with the status, the last batch completes the request
+		status := models.RequestProcessing
+		if index+count == validatorRequest.NumValidators {
+			status = models.RequestSuccessful
+		}
+
+		unitOfWork := repository.NewUnitOfWork(db)
+		validatorKeys := make([]*models.ValidatorKey, 0, len(validators))
+		for i := range validators {
+			validatorKey := &models.ValidatorKey{
+				ValidatorRequestID:    validatorRequest.ID,
+				Key:                   validators[i].keypair.PublicKeyHex(),
+				SecretKey:             validators[i].keypair.SecretKeyHex(),
+				DerivationIndex:       index + uint(i),
+				WithdrawalCredentials: "0x" + hex.EncodeToString(validators[i].withdrawalCredentials[:]),
+				FeeRecipient:          validatorRequest.FeeRecipient,
+			}
+			if withdrawalKeypair := validators[i].withdrawalKeypair; withdrawalKeypair != nil {
+				validatorKey.WithdrawalPubkey = withdrawalKeypair.PublicKeyHex()
+				validatorKey.WithdrawalSecretKey = withdrawalKeypair.SecretKeyHex()
+			}
+
+			unitOfWork.AddValidatorKey(validatorKey)
+			validatorKeys = append(validatorKeys, validatorKey)
+		}
+		unitOfWork.ChangeStatus(validatorRequest, []models.RequestStatus{models.RequestProcessing}, status)
+		enqueueWebhook(unitOfWork, validatorRequest, status)
+
+		err = unitOfWork.Commit()
+		if errors.Is(err, repository.ErrStatusChanged) {
+			return ErrValidatorRequestCancelled
 		}
-		err := repository.CreateValidatorKey(db, &validatorKey)
 		if err != nil {
-			log.Printf("%s: %v", ErrCreatingValidatorKey, err)
-			updateValidatorStatus(db, validatorRequest, models.RequestFailed, requestLock)
+			return fmt.Errorf("%s: %w", ErrCreatingValidatorKey, err)
+		}
 
-			return
+		for _, validatorKey := range validatorKeys {
+			publishKey(validatorKey)
 		}
+		publishProgress(validatorRequest, index+count)
 	}
 
-	updateValidatorStatus(db, validatorRequest, models.RequestSuccessful, requestLock)
+	if validatorRequest.Status == models.RequestSuccessful {
+		return nil
+	}
+
+	// the request had no missing keys
+	return updateValidatorStatus(db, validatorRequest, models.RequestSuccessful)
 }
 
-func createValidator(keys *[]string, errs *[]error, wg *sync.WaitGroup, keyLock *sync.Mutex) {
+// deriveValidators derives count validators starting at index. The keys are derived concurrently,
+// bounded by the key generation workers shared by all requests.
+func deriveValidators(ctx context.Context, seed []byte, index uint, count uint, withdrawalAddress string) ([]derivedValidator, error) {
+	var errs []error
+	var wg sync.WaitGroup
+	var keyLock sync.Mutex
+
+	validators := make([]derivedValidator, count)
+
+	slots := keyGenerationSlots
+derive:
+	for i := range validators {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			break derive
+		}
+		// select picks at random when a slot is free and ctx is done, the slot must not be kept
+		if ctx.Err() != nil {
+			<-slots
+			break
+		}
+
+		wg.Add(1)
+		go func() {
+			defer func() { <-slots }()
+			createValidator(seed, index+uint(i), withdrawalAddress, &validators[i], &errs, &wg, &keyLock)
+		}()
+	}
+
+	wg.Wait() // wait for all validators to be created
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("%s: %v", ErrCreatingValidator, errs)
+	}
+
+	return validators, nil
+}
+
+func createValidator(seed []byte, index uint, withdrawalAddress string, validator *derivedValidator, errs *[]error, wg *sync.WaitGroup, keyLock *sync.Mutex) {
 	defer wg.Done()
 
-	time.Sleep(DelayTime)
+	keypair, err := keys.DeriveKeypair(seed, keys.SigningKeyPath(index))
+	if err != nil {
+		log.Printf("%s, index: %d, error: %v", ErrDerivingKeypair, index, err)
+
+		keyLock.Lock()
+		*errs = append(*errs, err)
+		keyLock.Unlock()
 
-	keyLock.Lock()
-	key, err := generateRandomString(32)
-	keyLock.Unlock()
+		return
+	}
 
+	credentials, withdrawalKeypair, err := withdrawalCredentials(seed, index, withdrawalAddress)
 	if err != nil {
-		log.Printf("%s: %v", ErrGeneratingRandomString, err)
+		log.Printf("%s, index: %d, error: %v", ErrDerivingWithdrawalCredentials, index, err)
+
+		keyLock.Lock()
 		*errs = append(*errs, err)
+		keyLock.Unlock()
+
 		return
 	}
 
-	*keys = append(*keys, key)
+	validator.keypair = keypair
+	validator.withdrawalCredentials = credentials
+	validator.withdrawalKeypair = withdrawalKeypair
 }
 
-func updateValidatorStatus(db *gorm.DB, validatorRequest *models.ValidatorRequest, status models.RequestStatus, lock *sync.Mutex) {
-	validatorRequest.Status = status
+// withdrawalCredentials returns 0x01 credentials when the request has a withdrawal address and
+// 0x00 credentials of the EIP-2334 withdrawal key m/12381/3600/i/0 otherwise, together with the key.
+func withdrawalCredentials(seed []byte, index uint, withdrawalAddress string) ([32]byte, *keys.Keypair, error) {
+	if withdrawalAddress != "" {
+		address, err := utils.ParseAddress(withdrawalAddress)
+		if err != nil {
+			return [32]byte{}, nil, err
+		}
 
-	lock.Lock()
-	err := repository.UpdateValidatorRequest(db, validatorRequest)
-	lock.Unlock()
+		return eth2.ExecutionWithdrawalCredentials(address), nil, nil
+	}
 
+	withdrawalKeypair, err := keys.DeriveKeypair(seed, keys.WithdrawalKeyPath(index))
 	if err != nil {
-		log.Printf("%s, requestID: %s, error: %v", ErrUpdatingValidatorRequestStatus, validatorRequest.RequestUUID, err)
+		return [32]byte{}, nil, err
 	}
+
+	withdrawalPubkey := withdrawalKeypair.Public.Serialize()
+	return eth2.BLSWithdrawalCredentials(withdrawalPubkey[:]), withdrawalKeypair, nil
 }
 
-func generateRandomString(length int) (string, error) {
-	const charset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
-	var sb strings.Builder
-	randNum := rand.New(rand.NewSource(time.Now().UnixNano()))
+// validatorStatusTransitions lists the statuses a request may be moved to from each status by its job.
+var validatorStatusTransitions = map[models.RequestStatus][]models.RequestStatus{
+	models.RequestProcessing: models.RequestsInProgress,
+	models.RequestSuccessful: {models.RequestProcessing},
+	models.RequestFailed:     models.RequestsInProgress,
+}
 
-	for i := 0; i < length; i++ {
-		sb.WriteByte(charset[randNum.Intn(len(charset))])
+// updateValidatorStatus moves a request in progress to status and queues the webhook of a completed
+// request. It fails with ErrValidatorRequestCancelled when the request was cancelled meanwhile.
+func updateValidatorStatus(db *gorm.DB, validatorRequest *models.ValidatorRequest, status models.RequestStatus) error {
+	unitOfWork := repository.NewUnitOfWork(db)
+	unitOfWork.ChangeStatus(validatorRequest, validatorStatusTransitions[status], status)
+	enqueueWebhook(unitOfWork, validatorRequest, status)
+
+	err := unitOfWork.Commit()
+	if errors.Is(err, repository.ErrStatusChanged) {
+		return ErrValidatorRequestCancelled
+	}
+	if err != nil {
+		return fmt.Errorf("%s, requestID: %s, error: %w", ErrUpdatingValidatorRequestStatus, validatorRequest.RequestUUID, err)
 	}
 
-	return sb.String(), nil
+	publishStatus(db, validatorRequest)
+	return nil
 }
@@ -0,0 +1,231 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"syscall"
+	"time"
+	"validator-service/internal/models"
+	"validator-service/internal/queue"
+	"validator-service/internal/repository"
+	"validator-service/internal/utils"
+)
+
+const WebhookJobKind = "webhook"
+
+const (
+	WebhookIDHeader        = "X-Webhook-ID"
+	WebhookTimestampHeader = "X-Webhook-Timestamp"
+	WebhookSignatureHeader = "X-Webhook-Signature"
+
+	webhookSignaturePrefix = "sha256="
+	// maxWebhookResponseSize bounds the part of the response body that is read before the connection is reused.
+	maxWebhookResponseSize = 64 << 10
+)
+
+const (
+	ErrDeliveringWebhook       = "Failed to deliver webhook"
+	ErrRecordingWebhookAttempt = "Failed to record webhook delivery"
+)
+
+var (
+	ErrWebhookSecretNotConfigured = errors.New("webhook secret is not configured")
+	ErrWebhookRejected            = errors.New("webhook rejected by callback URL")
+	ErrWebhookDestinationRefused  = errors.New("callback URL resolves to a loopback, private or link-local address")
+)
+
+// webhookEvents maps the statuses that notify the callback URL of a request to their event.
+var webhookEvents = map[models.RequestStatus]string{
+	models.RequestSuccessful: models.WebhookRequestSuccessful,
+	models.RequestFailed:     models.WebhookRequestFailed,
+}
+
+var webhookMaxAttempts uint = queue.DefaultMaxAttempts
+
+// SetWebhookMaxAttempts sets the number of attempts of webhooks queued from now on.
+func SetWebhookMaxAttempts(maxAttempts uint) {
+	webhookMaxAttempts = maxAttempts
+}
+
+// WebhookPayload is the JSON body posted to the callback URL of a request.
+type WebhookPayload struct {
+	Event         string               `json:"event"`
+	RequestId     string               `json:"request_id"`
+	Status        models.RequestStatus `json:"status"`
+	NumValidators uint                 `json:"num_validators"`
+	KeysGenerated uint                 `json:"keys_generated"`
+}
+
+// WebhookJob delivers the webhooks queued when a request with a callback URL completes. Every
+// attempt is recorded in the webhook deliveries of the request.
+type WebhookJob struct {
+	db     *gorm.DB
+	secret string
+	client *http.Client
+}
+
+// NewWebhookJob returns the webhook delivery job. Unless allowPrivateNetworks is set, connections to
+// loopback, private and link-local addresses are refused after the callback host was resolved, so that
+// tenants cannot reach internal services or cloud metadata endpoints with their callback URLs.
+func NewWebhookJob(db *gorm.DB, secret string, timeout time.Duration, allowPrivateNetworks bool) *WebhookJob {
+	dialer := &net.Dialer{Timeout: timeout}
+	if !allowPrivateNetworks {
+		dialer.Control = refusePrivateAddresses
+	}
+
+	return &WebhookJob{
+		db:     db,
+		secret: secret,
+		client: &http.Client{
+			// the transport does not use proxies, they would connect to the callback URL instead of the dialer
+			Transport: &http.Transport{
+				DialContext:         dialer.DialContext,
+				TLSHandshakeTimeout: timeout,
+				MaxIdleConnsPerHost: 2,
+				IdleConnTimeout:     90 * time.Second,
+			},
+			Timeout: timeout,
+			// redirects are not followed so that the signed payload is only sent to the callback URL
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+func (j *WebhookJob) Process(ctx context.Context, job *models.Job) error {
+	if j.secret == "" {
+		return ErrWebhookSecretNotConfigured
+	}
+
+	validatorRequest, err := repository.GetValidatorRequestByIDWithoutKeys(j.db, job.ReferenceID)
+	if err != nil {
+		log.Printf("%s, id: %d, error: %v", ErrLoadingValidatorRequest, job.ReferenceID, err)
+		return err
+	}
+
+	// the request was resumed after the webhook was queued, its next completion queues a new one
+	event, ok := webhookEvents[validatorRequest.Status]
+	if !ok || validatorRequest.CallbackURL == "" {
+		return nil
+	}
+
+	keysGenerated, err := repository.CountValidatorKeys(j.db, validatorRequest.ID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", ErrCountingValidatorKeys, err)
+	}
+
+	body, err := json.Marshal(&WebhookPayload{
+		Event:         event,
+		RequestId:     validatorRequest.RequestUUID,
+		Status:        validatorRequest.Status,
+		NumValidators: validatorRequest.NumValidators,
+		KeysGenerated: uint(keysGenerated),
+	})
+	if err != nil {
+		return err
+	}
+
+	statusCode, sendErr := j.send(ctx, validatorRequest.CallbackURL, job.ID, body)
+
+	delivery := &models.WebhookDelivery{
+		ValidatorRequestID: validatorRequest.ID,
+		JobID:              job.ID,
+		Event:              event,
+		URL:                validatorRequest.CallbackURL,
+		Attempt:            job.Attempts,
+		StatusCode:         statusCode,
+	}
+	if errors.Is(sendErr, ErrWebhookDestinationRefused) {
+		// the resolved address is left out, the delivery log is visible to the tenant
+		delivery.Error = ErrWebhookDestinationRefused.Error()
+	} else if sendErr != nil {
+		delivery.Error = sendErr.Error()
+	}
+	if err := repository.CreateWebhookDelivery(j.db, delivery); err != nil {
+		log.Printf("%s, requestID: %s, error: %v", ErrRecordingWebhookAttempt, validatorRequest.RequestUUID, err)
+	}
+
+	return sendErr
+}
+
+// Dead gives up the webhook once it ran out of attempts, the attempts stay in the delivery log.
+func (j *WebhookJob) Dead(job *models.Job, err error) {
+	log.Printf("%s, id: %d, error: %v", ErrDeliveringWebhook, job.ReferenceID, err)
+}
+
+// send posts the signed body to url. The id is the same for all attempts of a webhook, so that
+// receivers can drop duplicates. It returns the response status, or 0 when there was no response.
+func (j *WebhookJob) send(ctx context.Context, url string, id uint, body []byte) (int, error) {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(WebhookIDHeader, strconv.FormatUint(uint64(id), 10))
+	request.Header.Set(WebhookTimestampHeader, timestamp)
+	request.Header.Set(WebhookSignatureHeader, SignWebhook(j.secret, timestamp, body))
+
+	response, err := j.client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(response.Body, maxWebhookResponseSize))
+
+	if response.StatusCode < http.StatusOK || response.StatusCode >= http.StatusMultipleChoices {
+		return response.StatusCode, fmt.Errorf("%w, status: %d", ErrWebhookRejected, response.StatusCode)
+	}
+
+	return response.StatusCode, nil
+}
+
+// refusePrivateAddresses is the dialer control that refuses connections to addresses that are not public.
+func refusePrivateAddresses(network string, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+
+	if !utils.IsPublicAddress(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s", ErrWebhookDestinationRefused, address)
+	}
+
+	return nil
+}
+
+// SignWebhook returns the X-Webhook-Signature of a webhook, the hex encoded HMAC-SHA256 of
+// the timestamp and the body joined by a dot, keyed with the webhook secret.
+func SignWebhook(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return webhookSignaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// enqueueWebhook queues the notification of the callback URL of the request together with its
+// change to status in unitOfWork, so that no completion is stored without its webhook.
+func enqueueWebhook(unitOfWork *repository.UnitOfWork, validatorRequest *models.ValidatorRequest, status models.RequestStatus) {
+	if _, ok := webhookEvents[status]; !ok || validatorRequest.CallbackURL == "" {
+		return
+	}
+
+	unitOfWork.AddJob(queue.NewJob(WebhookJobKind, validatorRequest.ID, webhookMaxAttempts))
+}
@@ -0,0 +1,177 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"validator-service/internal/models"
+	"validator-service/internal/queue"
+	"validator-service/internal/repository"
+	"validator-service/internal/services"
+)
+
+const testWebhookSecret = "webhook-secret"
+
+// newWebhookJob stores a successful request notifying callbackURL together with its queued webhook.
+func newWebhookJob(t *testing.T, db *gorm.DB, callbackURL string) (*models.ValidatorRequest, *models.Job) {
+	validatorRequest := newValidatorRequest(t, db, 1, []models.ValidatorKey{{Key: "0x01"}})
+	_, err := repository.UpdateValidatorRequestStatus(db, validatorRequest, models.RequestsInProgress, models.RequestSuccessful)
+	assert.NoError(t, err)
+	assert.NoError(t, db.Model(validatorRequest).Update("callback_url", callbackURL).Error)
+
+	job := queue.NewJob(services.WebhookJobKind, validatorRequest.ID, 3)
+	assert.NoError(t, repository.CreateJob(db, job))
+
+	return validatorRequest, job
+}
+
+func TestWebhookRefusesPrivateAddresses(t *testing.T) {
+	db := setupTestDB(t)
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+	}))
+	defer server.Close()
+
+	validatorRequest, job := newWebhookJob(t, db, server.URL)
+	job.Attempts = 1
+
+	err := services.NewWebhookJob(db, testWebhookSecret, time.Second, false).Process(context.Background(), job)
+	assert.ErrorIs(t, err, services.ErrWebhookDestinationRefused)
+	assert.Zero(t, requests.Load())
+
+	deliveries, err := repository.GetWebhookDeliveries(db, validatorRequest.ID)
+	assert.NoError(t, err)
+	assert.Len(t, deliveries, 1)
+	assert.Zero(t, deliveries[0].StatusCode)
+	// the resolved address is not disclosed in the delivery log
+	assert.Equal(t, services.ErrWebhookDestinationRefused.Error(), deliveries[0].Error)
+}
+
+func TestSignWebhook(t *testing.T) {
+	signature := services.SignWebhook(testWebhookSecret, "1700000000", []byte(`{"event":"validator_request.successful"}`))
+	assert.Equal(t, "sha256=4eae2900767ce835c461a1e970e1d3370fb29608f321210ae85414edef712fb1", signature)
+}
+
+// webhookReceiver records the webhooks it receives and answers them with the given statuses in turn.
+type webhookReceiver struct {
+	mu       sync.Mutex
+	statuses []int
+	received []*http.Request
+	bodies   [][]byte
+}
+
+func (r *webhookReceiver) ServeHTTP(w http.ResponseWriter, request *http.Request) {
+	body, _ := io.ReadAll(request.Body)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	status := r.statuses[min(len(r.received), len(r.statuses)-1)]
+	r.received = append(r.received, request)
+	r.bodies = append(r.bodies, body)
+
+	w.WriteHeader(status)
+}
+
+func TestWebhookIsRetriedUntilItIsAcknowledged(t *testing.T) {
+	db := setupTestDB(t)
+
+	receiver := &webhookReceiver{statuses: []int{http.StatusServiceUnavailable, http.StatusNoContent}}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	validatorRequest, job := newWebhookJob(t, db, server.URL)
+
+	pool := queue.NewPool(db, queue.Config{
+		Workers:       1,
+		PollInterval:  10 * time.Millisecond,
+		LeaseDuration: time.Second,
+		BackoffBase:   time.Millisecond,
+		BackoffMax:    10 * time.Millisecond,
+	})
+	pool.Register(services.WebhookJobKind, services.NewWebhookJob(db, testWebhookSecret, time.Second, true))
+	ctx, cancel := context.WithCancel(context.Background())
+	pool.Start(ctx)
+	t.Cleanup(func() {
+		cancel()
+		pool.Wait()
+	})
+
+	var done models.Job
+	assert.Eventually(t, func() bool {
+		return db.First(&done, job.ID).Error == nil && done.Status == models.JobDone
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, uint(2), done.Attempts)
+
+	receiver.mu.Lock()
+	defer receiver.mu.Unlock()
+	assert.Len(t, receiver.received, 2)
+	for i, request := range receiver.received {
+		assert.Equal(t, http.MethodPost, request.Method)
+		assert.Equal(t, "application/json", request.Header.Get("Content-Type"))
+		// all attempts carry the same id, so that receivers can drop duplicates
+		assert.Equal(t, strconv.FormatUint(uint64(job.ID), 10), request.Header.Get(services.WebhookIDHeader))
+
+		timestamp := request.Header.Get(services.WebhookTimestampHeader)
+		assert.NotEmpty(t, timestamp)
+		assert.Equal(t, services.SignWebhook(testWebhookSecret, timestamp, receiver.bodies[i]), request.Header.Get(services.WebhookSignatureHeader))
+
+		var payload services.WebhookPayload
+		assert.NoError(t, json.Unmarshal(receiver.bodies[i], &payload))
+		assert.Equal(t, services.WebhookPayload{
+			Event:         models.WebhookRequestSuccessful,
+			RequestId:     validatorRequest.RequestUUID,
+			Status:        models.RequestSuccessful,
+			NumValidators: 1,
+			KeysGenerated: 1,
+		}, payload)
+	}
+
+	deliveries, err := repository.GetWebhookDeliveries(db, validatorRequest.ID)
+	assert.NoError(t, err)
+	assert.Len(t, deliveries, 2)
+	for i, delivery := range deliveries {
+		assert.Equal(t, uint(i+1), delivery.Attempt)
+		assert.Equal(t, job.ID, delivery.JobID)
+		assert.Equal(t, models.WebhookRequestSuccessful, delivery.Event)
+		assert.Equal(t, server.URL, delivery.URL)
+	}
+	assert.Equal(t, http.StatusServiceUnavailable, deliveries[0].StatusCode)
+	assert.Contains(t, deliveries[0].Error, services.ErrWebhookRejected.Error())
+	assert.Equal(t, http.StatusNoContent, deliveries[1].StatusCode)
+	assert.Empty(t, deliveries[1].Error)
+}
+
+func TestWebhookSkipsRequestsThatAreNotCompleted(t *testing.T) {
+	db := setupTestDB(t)
+
+	receiver := &webhookReceiver{statuses: []int{http.StatusNoContent}}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	validatorRequest, job := newWebhookJob(t, db, server.URL)
+	// validators were added to the request after its webhook was queued
+	_, err := repository.UpdateValidatorRequestStatus(db, validatorRequest, []models.RequestStatus{models.RequestSuccessful}, models.RequestStarted)
+	assert.NoError(t, err)
+
+	assert.NoError(t, services.NewWebhookJob(db, testWebhookSecret, time.Second, true).Process(context.Background(), job))
+
+	receiver.mu.Lock()
+	defer receiver.mu.Unlock()
+	assert.Empty(t, receiver.received)
+
+	deliveries, err := repository.GetWebhookDeliveries(db, validatorRequest.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, deliveries)
+}
@@ -0,0 +1,58 @@
+package services
+
+import (
+	"errors"
+	"validator-service/internal/eth2"
+	"validator-service/internal/keys"
+	"validator-service/internal/models"
+	"validator-service/internal/utils"
+)
+
+var (
+	ErrNotBLSWithdrawalCredentials = errors.New("validator does not have BLS withdrawal credentials")
+	ErrWithdrawalKeyMismatch       = errors.New("withdrawal key does not match the withdrawal credentials")
+)
+
+// SignBLSToExecutionChange signs a change of the 0x00 withdrawal credentials of the validator with the key and
+// index to 0x01 credentials of toExecutionAddress. It is signed with the BLS withdrawal key of the validator.
+func SignBLSToExecutionChange(validatorRequest *models.ValidatorRequest, validatorKey *models.ValidatorKey, validatorIndex uint64, toExecutionAddress string, network *eth2.Network) (*eth2.SignedBLSToExecutionChange, error) {
+	address, err := utils.ParseAddress(toExecutionAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	credentials, err := keyWithdrawalCredentials(validatorRequest, validatorKey)
+	if err != nil {
+		return nil, err
+	}
+	if credentials[0] != eth2.BLSWithdrawalPrefix {
+		return nil, ErrNotBLSWithdrawalCredentials
+	}
+
+	withdrawalKeypair, err := keyWithdrawalKeypair(validatorRequest, validatorKey)
+	if err != nil {
+		return nil, err
+	}
+
+	withdrawalPubkey := withdrawalKeypair.Public.Serialize()
+	if eth2.BLSWithdrawalCredentials(withdrawalPubkey[:]) != credentials {
+		return nil, ErrWithdrawalKeyMismatch
+	}
+
+	return eth2.NewSignedBLSToExecutionChange(withdrawalKeypair, validatorIndex, address, network), nil
+}
+
+// keyWithdrawalKeypair returns the stored withdrawal key of the key, deriving it from the request
+// for keys created before withdrawal keys were stored.
+func keyWithdrawalKeypair(validatorRequest *models.ValidatorRequest, validatorKey *models.ValidatorKey) (*keys.Keypair, error) {
+	if validatorKey.WithdrawalSecretKey != "" {
+		return keys.KeypairFromSecretHex(validatorKey.WithdrawalSecretKey)
+	}
+
+	seed, err := keys.SeedFromMnemonic(validatorRequest.Mnemonic, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return keys.DeriveKeypair(seed, keys.WithdrawalKeyPath(validatorKey.DerivationIndex))
+}
@@ -0,0 +1,230 @@
+package slashing
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"validator-service/internal/eth2"
+	"validator-service/internal/models"
+	"validator-service/internal/repository"
+)
+
+// InterchangeFormatVersion is the version of the EIP-3076 interchange format that is imported and exported.
+const InterchangeFormatVersion = "5"
+
+const interchangeBatchSize = 500
+
+var ErrUnsupportedInterchangeVersion = errors.New("unsupported interchange format version")
+
+// Interchange is the slashing protection interchange format of EIP-3076.
+type Interchange struct {
+	Metadata InterchangeMetadata `json:"metadata"`
+	Data     []InterchangeData   `json:"data"`
+}
+
+type InterchangeMetadata struct {
+	InterchangeFormatVersion string    `json:"interchange_format_version"`
+	GenesisValidatorsRoot    eth2.Root `json:"genesis_validators_root"`
+}
+
+// InterchangeData is the signing history of a single validator key.
+type InterchangeData struct {
+	Pubkey             eth2.BLSPubkey           `json:"pubkey"`
+	SignedBlocks       []InterchangeBlock       `json:"signed_blocks"`
+	SignedAttestations []InterchangeAttestation `json:"signed_attestations"`
+}
+
+type InterchangeBlock struct {
+	Slot        eth2.Uint64 `json:"slot"`
+	SigningRoot *eth2.Root  `json:"signing_root,omitempty"`
+}
+
+type InterchangeAttestation struct {
+	SourceEpoch eth2.Uint64 `json:"source_epoch"`
+	TargetEpoch eth2.Uint64 `json:"target_epoch"`
+	SigningRoot *eth2.Root  `json:"signing_root,omitempty"`
+}
+
+// ImportResult lists the keys whose history was imported and the keys that are not stored by the service.
+type ImportResult struct {
+	Imported       []string `json:"imported"`
+	UnknownPubkeys []string `json:"unknown_pubkeys"`
+}
+
+// Import adds the signing history of the interchange to the history of the keys of the tenant, so that they
+// can be moved from another signer. Records that are already present are kept.
+func Import(db *gorm.DB, tenantID uint, interchange *Interchange, network *eth2.Network) (*ImportResult, error) {
+	if interchange.Metadata.InterchangeFormatVersion != InterchangeFormatVersion {
+		return nil, ErrUnsupportedInterchangeVersion
+	}
+	if interchange.Metadata.GenesisValidatorsRoot != network.GenesisValidatorsRoot {
+		return nil, eth2.ErrGenesisValidatorsRootMismatch
+	}
+
+	result := &ImportResult{Imported: []string{}, UnknownPubkeys: []string{}}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		for _, data := range interchange.Data {
+			pubkey, _ := data.Pubkey.MarshalText()
+
+			var validatorKey models.ValidatorKey
+			err := tx.
+				Select("id").
+				Where("key = ? AND validator_request_id IN (?)", string(pubkey), repository.TenantValidatorRequestIDs(tx, tenantID)).
+				First(&validatorKey).
+				Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				result.UnknownPubkeys = append(result.UnknownPubkeys, string(pubkey))
+				continue
+			}
+			if err != nil {
+				return err
+			}
+
+			if err := importHistory(tx, validatorKey.ID, &data); err != nil {
+				return err
+			}
+			result.Imported = append(result.Imported, string(pubkey))
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func importHistory(tx *gorm.DB, validatorKeyID uint, data *InterchangeData) error {
+	blocks := make([]models.SignedBlock, 0, len(data.SignedBlocks))
+	for _, block := range data.SignedBlocks {
+		blocks = append(blocks, models.SignedBlock{
+			ValidatorKeyID: validatorKeyID,
+			Slot:           uint64(block.Slot),
+			SigningRoot:    encodeOptionalRoot(block.SigningRoot),
+		})
+	}
+
+	attestations := make([]models.SignedAttestation, 0, len(data.SignedAttestations))
+	for _, attestation := range data.SignedAttestations {
+		attestations = append(attestations, models.SignedAttestation{
+			ValidatorKeyID: validatorKeyID,
+			SourceEpoch:    uint64(attestation.SourceEpoch),
+			TargetEpoch:    uint64(attestation.TargetEpoch),
+			SigningRoot:    encodeOptionalRoot(attestation.SigningRoot),
+		})
+	}
+
+	// a slot or target epoch that is already recorded stays refused for other messages, so keeping
+	// the existing record is as safe as keeping both
+	if len(blocks) > 0 {
+		err := tx.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(&blocks, interchangeBatchSize).Error
+		if err != nil {
+			return err
+		}
+	}
+	if len(attestations) > 0 {
+		err := tx.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(&attestations, interchangeBatchSize).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Export returns the complete signing history of all keys of the tenant with any history.
+func Export(db *gorm.DB, tenantID uint, network *eth2.Network) (*Interchange, error) {
+	var validatorKeys []models.ValidatorKey
+	err := db.
+		Select("id", "key").
+		Where("validator_request_id IN (?)", repository.TenantValidatorRequestIDs(db, tenantID)).
+		Where("id IN (?) OR id IN (?)",
+			db.Model(&models.SignedBlock{}).Select("validator_key_id"),
+			db.Model(&models.SignedAttestation{}).Select("validator_key_id"),
+		).
+		Order("id").
+		Find(&validatorKeys).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	interchange := &Interchange{
+		Metadata: InterchangeMetadata{
+			InterchangeFormatVersion: InterchangeFormatVersion,
+			GenesisValidatorsRoot:    network.GenesisValidatorsRoot,
+		},
+		Data: make([]InterchangeData, 0, len(validatorKeys)),
+	}
+
+	for _, validatorKey := range validatorKeys {
+		data, err := exportHistory(db, &validatorKey)
+		if err != nil {
+			return nil, err
+		}
+		interchange.Data = append(interchange.Data, *data)
+	}
+
+	return interchange, nil
+}
+
+func exportHistory(db *gorm.DB, validatorKey *models.ValidatorKey) (*InterchangeData, error) {
+	data := &InterchangeData{
+		SignedBlocks:       []InterchangeBlock{},
+		SignedAttestations: []InterchangeAttestation{},
+	}
+	if err := data.Pubkey.UnmarshalText([]byte(validatorKey.Key)); err != nil {
+		return nil, err
+	}
+
+	var blocks []models.SignedBlock
+	if err := db.Where("validator_key_id = ?", validatorKey.ID).Order("slot").Find(&blocks).Error; err != nil {
+		return nil, err
+	}
+	for _, block := range blocks {
+		signingRoot, err := decodeOptionalRoot(block.SigningRoot)
+		if err != nil {
+			return nil, err
+		}
+		data.SignedBlocks = append(data.SignedBlocks, InterchangeBlock{Slot: eth2.Uint64(block.Slot), SigningRoot: signingRoot})
+	}
+
+	var attestations []models.SignedAttestation
+	if err := db.Where("validator_key_id = ?", validatorKey.ID).Order("target_epoch").Find(&attestations).Error; err != nil {
+		return nil, err
+	}
+	for _, attestation := range attestations {
+		signingRoot, err := decodeOptionalRoot(attestation.SigningRoot)
+		if err != nil {
+			return nil, err
+		}
+		data.SignedAttestations = append(data.SignedAttestations, InterchangeAttestation{
+			SourceEpoch: eth2.Uint64(attestation.SourceEpoch),
+			TargetEpoch: eth2.Uint64(attestation.TargetEpoch),
+			SigningRoot: signingRoot,
+		})
+	}
+
+	return data, nil
+}
+
+func encodeOptionalRoot(root *eth2.Root) string {
+	if root == nil {
+		return ""
+	}
+	return encodeRoot(*root)
+}
+
+func decodeOptionalRoot(root string) (*eth2.Root, error) {
+	if root == "" {
+		return nil, nil
+	}
+
+	var decoded eth2.Root
+	if err := decoded.UnmarshalText([]byte(root)); err != nil {
+		return nil, err
+	}
+	return &decoded, nil
+}
@@ -0,0 +1,115 @@
+package slashing_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"validator-service/internal/eth2"
+	"validator-service/internal/slashing"
+)
+
+const unknownPubkey = "0xb74882a46bd0025acb5bad90d2e5acf13e6d515793584189f63b672886a148ab88fbfc967ec55b37c9c87cb8b8f1afec"
+
+const testInterchange = `{
+	"metadata": {
+		"interchange_format_version": "5",
+		"genesis_validators_root": "0x4b363db94e286120d76eb905340fdd4e54bfe9f06bf33ff6cf5ad27f511bfe95"
+	},
+	"data": [
+		{
+			"pubkey": "` + testPubkey + `",
+			"signed_blocks": [
+				{"slot": "81952", "signing_root": "0x4ff6f743a43f3b4f95350831aeaf0a122a1a392922c45d804280284a69eb850b"},
+				{"slot": "81951"}
+			],
+			"signed_attestations": [
+				{"source_epoch": "2290", "target_epoch": "3007", "signing_root": "0x587d6a4f59a58fe24f406e0502413e77fe1babddee641fda30034ed37ecc884d"},
+				{"source_epoch": "2290", "target_epoch": "3008"}
+			]
+		},
+		{
+			"pubkey": "` + unknownPubkey + `",
+			"signed_blocks": [{"slot": "1"}],
+			"signed_attestations": []
+		}
+	]
+}`
+
+func parseInterchange(t *testing.T, encoded string) *slashing.Interchange {
+	var interchange slashing.Interchange
+	assert.NoError(t, json.Unmarshal([]byte(encoded), &interchange))
+	return &interchange
+}
+
+func TestImportExport(t *testing.T) {
+	db, validatorKeyID := setupTestDB(t)
+	network, err := eth2.GetNetwork(eth2.NetworkMainnet)
+	assert.NoError(t, err)
+
+	result, err := slashing.Import(db, testTenantID, parseInterchange(t, testInterchange), network)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{testPubkey}, result.Imported)
+	assert.Equal(t, []string{unknownPubkey}, result.UnknownPubkeys)
+
+	// importing the same history again keeps a single copy
+	_, err = slashing.Import(db, testTenantID, parseInterchange(t, testInterchange), network)
+	assert.NoError(t, err)
+
+	exported, err := slashing.Export(db, testTenantID, network)
+	assert.NoError(t, err)
+	assert.Len(t, exported.Data, 1)
+	assert.Len(t, exported.Data[0].SignedBlocks, 2)
+	assert.Equal(t, eth2.Uint64(81951), exported.Data[0].SignedBlocks[0].Slot)
+	assert.Nil(t, exported.Data[0].SignedBlocks[0].SigningRoot)
+	assert.Len(t, exported.Data[0].SignedAttestations, 2)
+
+	encoded, err := json.Marshal(exported)
+	assert.NoError(t, err)
+	expected := parseInterchange(t, testInterchange)
+	expected.Data = expected.Data[:1]
+	expected.Data[0].SignedBlocks[0], expected.Data[0].SignedBlocks[1] = expected.Data[0].SignedBlocks[1], expected.Data[0].SignedBlocks[0]
+	assert.Equal(t, expected, parseInterchange(t, string(encoded)))
+
+	// the imported history is enforced, also for records without signing root
+	var signingRoot eth2.Root
+	assert.NoError(t, signingRoot.UnmarshalText([]byte("0x4ff6f743a43f3b4f95350831aeaf0a122a1a392922c45d804280284a69eb850b")))
+	assert.NoError(t, slashing.CheckAndRecordBlock(db, validatorKeyID, 81952, signingRoot))
+	assert.ErrorIs(t, slashing.CheckAndRecordBlock(db, validatorKeyID, 81951, signingRoot), slashing.ErrDoubleProposal)
+	assert.ErrorIs(t, slashing.CheckAndRecordBlock(db, validatorKeyID, 81950, signingRoot), slashing.ErrLowSlot)
+	assert.ErrorIs(t, slashing.CheckAndRecordAttestation(db, validatorKeyID, 2290, 3008, signingRoot), slashing.ErrDoubleVote)
+	assert.ErrorIs(t, slashing.CheckAndRecordAttestation(db, validatorKeyID, 2289, 3009, signingRoot), slashing.ErrSurroundingVote)
+	assert.NoError(t, slashing.CheckAndRecordAttestation(db, validatorKeyID, 3008, 3009, signingRoot))
+}
+
+func TestImportExportAreScopedToTenant(t *testing.T) {
+	db, _ := setupTestDB(t)
+	network, err := eth2.GetNetwork(eth2.NetworkMainnet)
+	assert.NoError(t, err)
+
+	result, err := slashing.Import(db, otherTenantID, parseInterchange(t, testInterchange), network)
+	assert.NoError(t, err)
+	assert.Empty(t, result.Imported)
+	assert.Equal(t, []string{testPubkey, unknownPubkey}, result.UnknownPubkeys)
+
+	_, err = slashing.Import(db, testTenantID, parseInterchange(t, testInterchange), network)
+	assert.NoError(t, err)
+
+	exported, err := slashing.Export(db, otherTenantID, network)
+	assert.NoError(t, err)
+	assert.Empty(t, exported.Data)
+}
+
+func TestImportRejectsOtherChains(t *testing.T) {
+	db, _ := setupTestDB(t)
+	network, err := eth2.GetNetwork(eth2.NetworkHoodi)
+	assert.NoError(t, err)
+
+	_, err = slashing.Import(db, testTenantID, parseInterchange(t, testInterchange), network)
+	assert.ErrorIs(t, err, eth2.ErrGenesisValidatorsRootMismatch)
+
+	interchange := parseInterchange(t, testInterchange)
+	interchange.Metadata.InterchangeFormatVersion = "4"
+	_, err = slashing.Import(db, testTenantID, interchange, network)
+	assert.ErrorIs(t, err, slashing.ErrUnsupportedInterchangeVersion)
+}
@@ -0,0 +1,173 @@
+// Package slashing protects validator keys from signing slashable blocks and attestations.
+// It implements the rules and the interchange format of EIP-3076.
+package slashing
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"validator-service/internal/eth2"
+	"validator-service/internal/models"
+)
+
+// ErrNotSafeToSign is wrapped by all errors of messages refused by slashing protection.
+var ErrNotSafeToSign = errors.New("refused by slashing protection")
+
+var (
+	ErrDoubleProposal    = fmt.Errorf("%w: a different block was signed for the slot", ErrNotSafeToSign)
+	ErrLowSlot           = fmt.Errorf("%w: slot is not after the lowest signed slot", ErrNotSafeToSign)
+	ErrSourceAfterTarget = fmt.Errorf("%w: source epoch is after target epoch", ErrNotSafeToSign)
+	ErrDoubleVote        = fmt.Errorf("%w: a different attestation was signed for the target epoch", ErrNotSafeToSign)
+	ErrSurroundingVote   = fmt.Errorf("%w: attestation surrounds a signed attestation", ErrNotSafeToSign)
+	ErrSurroundedVote    = fmt.Errorf("%w: attestation is surrounded by a signed attestation", ErrNotSafeToSign)
+	ErrLowSourceEpoch    = fmt.Errorf("%w: source epoch is before the lowest signed source epoch", ErrNotSafeToSign)
+	ErrLowTargetEpoch    = fmt.Errorf("%w: target epoch is not after the lowest signed target epoch", ErrNotSafeToSign)
+)
+
+// CheckAndRecordBlock records the block proposal if it is safe to sign and returns an error wrapping
+// ErrNotSafeToSign otherwise. Signing the same block again is safe.
+func CheckAndRecordBlock(db *gorm.DB, validatorKeyID uint, slot uint64, signingRoot eth2.Root) error {
+	root := encodeRoot(signingRoot)
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := lockValidatorKey(tx, validatorKeyID); err != nil {
+			return err
+		}
+
+		var signed []models.SignedBlock
+		err := tx.
+			Where("validator_key_id = ? AND slot = ?", validatorKeyID, slot).
+			Find(&signed).
+			Error
+		if err != nil {
+			return err
+		}
+		if len(signed) > 0 {
+			if signed[0].SigningRoot == root {
+				return nil
+			}
+			return ErrDoubleProposal
+		}
+
+		var minSlot sql.NullInt64
+		err = tx.
+			Model(&models.SignedBlock{}).
+			Where("validator_key_id = ?", validatorKeyID).
+			Select("MIN(slot)").
+			Scan(&minSlot).
+			Error
+		if err != nil {
+			return err
+		}
+		if minSlot.Valid && slot <= uint64(minSlot.Int64) {
+			return ErrLowSlot
+		}
+
+		return tx.Create(&models.SignedBlock{ValidatorKeyID: validatorKeyID, Slot: slot, SigningRoot: root}).Error
+	})
+}
+
+// CheckAndRecordAttestation records the attestation if it is safe to sign and returns an error wrapping
+// ErrNotSafeToSign otherwise. Signing the same attestation again is safe.
+func CheckAndRecordAttestation(db *gorm.DB, validatorKeyID uint, sourceEpoch uint64, targetEpoch uint64, signingRoot eth2.Root) error {
+	if sourceEpoch > targetEpoch {
+		return ErrSourceAfterTarget
+	}
+
+	root := encodeRoot(signingRoot)
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := lockValidatorKey(tx, validatorKeyID); err != nil {
+			return err
+		}
+
+		var signed []models.SignedAttestation
+		err := tx.
+			Where("validator_key_id = ? AND target_epoch = ?", validatorKeyID, targetEpoch).
+			Find(&signed).
+			Error
+		if err != nil {
+			return err
+		}
+		if len(signed) > 0 {
+			if signed[0].SigningRoot == root && signed[0].SourceEpoch == sourceEpoch {
+				return nil
+			}
+			return ErrDoubleVote
+		}
+
+		surrounded, err := countAttestations(tx, validatorKeyID, "source_epoch < ? AND target_epoch > ?", sourceEpoch, targetEpoch)
+		if err != nil {
+			return err
+		}
+		if surrounded > 0 {
+			return ErrSurroundedVote
+		}
+
+		surrounding, err := countAttestations(tx, validatorKeyID, "source_epoch > ? AND target_epoch < ?", sourceEpoch, targetEpoch)
+		if err != nil {
+			return err
+		}
+		if surrounding > 0 {
+			return ErrSurroundingVote
+		}
+
+		// imported histories can be incomplete, nothing below the lowest recorded epochs is signed
+		var watermark struct {
+			MinSourceEpoch sql.NullInt64
+			MinTargetEpoch sql.NullInt64
+		}
+		err = tx.
+			Model(&models.SignedAttestation{}).
+			Where("validator_key_id = ?", validatorKeyID).
+			Select("MIN(source_epoch) AS min_source_epoch, MIN(target_epoch) AS min_target_epoch").
+			Scan(&watermark).
+			Error
+		if err != nil {
+			return err
+		}
+		if watermark.MinSourceEpoch.Valid && sourceEpoch < uint64(watermark.MinSourceEpoch.Int64) {
+			return ErrLowSourceEpoch
+		}
+		if watermark.MinTargetEpoch.Valid && targetEpoch <= uint64(watermark.MinTargetEpoch.Int64) {
+			return ErrLowTargetEpoch
+		}
+
+		return tx.Create(&models.SignedAttestation{
+			ValidatorKeyID: validatorKeyID,
+			SourceEpoch:    sourceEpoch,
+			TargetEpoch:    targetEpoch,
+			SigningRoot:    root,
+		}).Error
+	})
+}
+
+// lockValidatorKey serializes the checks of concurrent signing requests for a key across replicas.
+// SQLite ignores the lock, but fails one of two concurrent write transactions instead.
+func lockValidatorKey(tx *gorm.DB, validatorKeyID uint) error {
+	return tx.
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id").
+		First(&models.ValidatorKey{}, validatorKeyID).
+		Error
+}
+
+func countAttestations(tx *gorm.DB, validatorKeyID uint, query string, args ...interface{}) (int64, error) {
+	var count int64
+	err := tx.
+		Model(&models.SignedAttestation{}).
+		Where("validator_key_id = ?", validatorKeyID).
+		Where(query, args...).
+		Count(&count).
+		Error
+
+	return count, err
+}
+
+func encodeRoot(root eth2.Root) string {
+	encoded, _ := root.MarshalText()
+	return string(encoded)
+}
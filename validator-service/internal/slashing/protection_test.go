@@ -0,0 +1,140 @@
+package slashing_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"validator-service/internal/eth2"
+	"validator-service/internal/migrations"
+	"validator-service/internal/models"
+	"validator-service/internal/repository"
+	"validator-service/internal/slashing"
+)
+
+const testPubkey = "0xa15715fb1356854118acfc6fcbba29a772144c893188123747440945287bb50f9a80e5d4995294d0c58ada6ec0c1d1f5"
+
+const (
+	testTenantID  uint = 1
+	otherTenantID uint = 2
+)
+
+// setupTestDB returns an in-memory database with a single validator key of the test tenant.
+func setupTestDB(t *testing.T) (*gorm.DB, uint) {
+	keyring, err := repository.NewKeyring(make([]byte, repository.MasterKeyLength))
+	assert.NoError(t, err)
+	repository.SetKeyring(keyring)
+
+	db, err := repository.OpenDatabase(repository.DriverSQLite, ":memory:")
+	assert.NoError(t, err)
+	_, err = migrations.Up(db)
+	assert.NoError(t, err)
+
+	validatorRequest := models.ValidatorRequest{TenantID: testTenantID, RequestUUID: "test_uuid", Keys: []models.ValidatorKey{{Key: testPubkey}}}
+	assert.NoError(t, repository.CreateValidatorRequest(db, &validatorRequest))
+	return db, validatorRequest.Keys[0].ID
+}
+
+func root(i byte) eth2.Root {
+	var root eth2.Root
+	root[31] = i
+	return root
+}
+
+type signedBlock struct {
+	slot uint64
+	root byte
+}
+
+type signedAttestation struct {
+	source uint64
+	target uint64
+	root   byte
+}
+
+func TestCheckAndRecordBlock(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		history []signedBlock
+		block   signedBlock
+		err     error
+	}{
+		{"first block", nil, signedBlock{10, 1}, nil},
+		{"later slot", []signedBlock{{10, 1}}, signedBlock{11, 2}, nil},
+		{"slot between signed slots", []signedBlock{{10, 1}, {20, 2}}, signedBlock{15, 3}, nil},
+		{"same block again", []signedBlock{{10, 1}, {20, 2}}, signedBlock{20, 2}, nil},
+		{"double proposal", []signedBlock{{10, 1}}, signedBlock{10, 2}, slashing.ErrDoubleProposal},
+		{"below lowest slot", []signedBlock{{10, 1}}, signedBlock{9, 2}, slashing.ErrLowSlot},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			db, validatorKeyID := setupTestDB(t)
+			for _, block := range test.history {
+				assert.NoError(t, slashing.CheckAndRecordBlock(db, validatorKeyID, block.slot, root(block.root)))
+			}
+
+			err := slashing.CheckAndRecordBlock(db, validatorKeyID, test.block.slot, root(test.block.root))
+			if test.err == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, test.err)
+			assert.ErrorIs(t, err, slashing.ErrNotSafeToSign)
+		})
+	}
+}
+
+func TestCheckAndRecordAttestation(t *testing.T) {
+	for _, test := range []struct {
+		name        string
+		history     []signedAttestation
+		attestation signedAttestation
+		err         error
+	}{
+		{"first attestation", nil, signedAttestation{0, 1, 1}, nil},
+		{"next epoch", []signedAttestation{{3, 10, 1}}, signedAttestation{10, 11, 2}, nil},
+		{"same attestation again", []signedAttestation{{3, 10, 1}}, signedAttestation{3, 10, 1}, nil},
+		{"source after target", nil, signedAttestation{5, 4, 1}, slashing.ErrSourceAfterTarget},
+		{"double vote", []signedAttestation{{3, 10, 1}}, signedAttestation{3, 10, 2}, slashing.ErrDoubleVote},
+		{"double vote with other source", []signedAttestation{{3, 10, 1}}, signedAttestation{4, 10, 1}, slashing.ErrDoubleVote},
+		{"surrounding vote", []signedAttestation{{3, 4, 1}}, signedAttestation{2, 5, 2}, slashing.ErrSurroundingVote},
+		{"surrounded vote", []signedAttestation{{1, 6, 1}}, signedAttestation{2, 5, 2}, slashing.ErrSurroundedVote},
+		{"source below lowest source", []signedAttestation{{3, 10, 1}}, signedAttestation{2, 3, 2}, slashing.ErrLowSourceEpoch},
+		{"target below lowest target", []signedAttestation{{3, 10, 1}}, signedAttestation{3, 9, 2}, slashing.ErrLowTargetEpoch},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			db, validatorKeyID := setupTestDB(t)
+			for _, attestation := range test.history {
+				err := slashing.CheckAndRecordAttestation(db, validatorKeyID, attestation.source, attestation.target, root(attestation.root))
+				assert.NoError(t, err)
+			}
+
+			attestation := test.attestation
+			err := slashing.CheckAndRecordAttestation(db, validatorKeyID, attestation.source, attestation.target, root(attestation.root))
+			if test.err == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, test.err)
+			assert.ErrorIs(t, err, slashing.ErrNotSafeToSign)
+		})
+	}
+}
+
+func TestCheckAndRecordRecordsOnlySafeMessages(t *testing.T) {
+	db, validatorKeyID := setupTestDB(t)
+
+	assert.NoError(t, slashing.CheckAndRecordAttestation(db, validatorKeyID, 3, 10, root(1)))
+	assert.ErrorIs(t, slashing.CheckAndRecordAttestation(db, validatorKeyID, 3, 10, root(2)), slashing.ErrDoubleVote)
+	assert.NoError(t, slashing.CheckAndRecordAttestation(db, validatorKeyID, 3, 10, root(1)))
+
+	var count int64
+	db.Model(&models.SignedAttestation{}).Count(&count)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestUnknownValidatorKey(t *testing.T) {
+	db, validatorKeyID := setupTestDB(t)
+
+	err := slashing.CheckAndRecordBlock(db, validatorKeyID+1, 10, root(1))
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
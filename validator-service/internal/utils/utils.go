@@ -1,8 +1,72 @@
 package utils
 
-import "regexp"
+import (
+	"encoding/hex"
+	"errors"
+	"net/netip"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var ErrInvalidAddress = errors.New("invalid address")
 
 func ValidateAddress(address string) bool {
 	re := regexp.MustCompile("^0x[0-9a-fA-F]{40}$")
 	return re.MatchString(address)
 }
+
+func ParseAddress(address string) ([20]byte, error) {
+	var parsed [20]byte
+	if !ValidateAddress(address) {
+		return parsed, ErrInvalidAddress
+	}
+
+	_, err := hex.Decode(parsed[:], []byte(address[2:]))
+	return parsed, err
+}
+
+// MaxURLLength is the length limit of URLs accepted by ValidateURL.
+const MaxURLLength = 2048
+
+// ValidateURL reports whether rawURL is an absolute http or https URL.
+func ValidateURL(rawURL string) bool {
+	if len(rawURL) > MaxURLLength {
+		return false
+	}
+
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+
+	return (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+}
+
+// sharedAddressSpace is the carrier-grade NAT range of RFC 6598, which is not routable on the internet either.
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// IsPublicAddress reports whether addr is a global unicast address outside of the loopback,
+// private, link-local and shared address ranges.
+func IsPublicAddress(addr netip.Addr) bool {
+	addr = addr.Unmap()
+
+	return addr.IsGlobalUnicast() && !addr.IsPrivate() && !sharedAddressSpace.Contains(addr)
+}
+
+// IsPrivateURL reports whether the host of rawURL is localhost or an IP address that is not public.
+// Host names are only resolved when connecting, so they have to be checked again at that point.
+func IsPrivateURL(rawURL string) bool {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(parsed.Hostname()), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return true
+	}
+
+	addr, err := netip.ParseAddr(host)
+	return err == nil && !IsPublicAddress(addr)
+}
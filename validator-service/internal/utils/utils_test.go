@@ -0,0 +1,40 @@
+package utils_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"validator-service/internal/utils"
+)
+
+func TestIsPrivateURL(t *testing.T) {
+	tests := []struct {
+		url     string
+		private bool
+	}{
+		{"https://example.com/hooks", false},
+		{"https://93.184.215.14/hooks", false},
+		{"https://[2606:2800:21f:cb07:6820:80da:af6b:8b2c]/hooks", false},
+		{"http://localhost:8080/hooks", true},
+		{"http://LOCALHOST./hooks", true},
+		{"http://api.localhost/hooks", true},
+		{"http://127.0.0.1/hooks", true},
+		{"http://[::1]/hooks", true},
+		{"http://0.0.0.0/hooks", true},
+		{"http://10.1.2.3/hooks", true},
+		{"http://172.16.0.1/hooks", true},
+		{"http://192.168.1.1/hooks", true},
+		{"http://100.64.0.1/hooks", true},
+		{"http://169.254.169.254/latest/meta-data", true},
+		{"http://[fe80::1]/hooks", true},
+		{"http://[fd00:ec2::254]/hooks", true},
+		{"http://[::ffff:127.0.0.1]/hooks", true},
+		{"http://224.0.0.1/hooks", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.url, func(t *testing.T) {
+			assert.Equal(t, test.private, utils.IsPrivateURL(test.url))
+		})
+	}
+}